@@ -17,6 +17,10 @@ import (
 // @in header
 // @name Authorization
 // @description Введите "Bearer {token}" для авторизации
+// @securityDefinitions.apikey ServiceAuth
+// @in header
+// @name X-Service-Token
+// @description Токен сервиса из internal.servicetokens для маршрутов /internal
 func main() {
 	app.Run()
 	log.Println("Server exited properly")
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"auth_service/internal/config"
+	userrepo "auth_service/internal/repository/user"
+	"auth_service/internal/storage/postgresql"
+)
+
+// Приводит номера телефонов пользователей к E.164 с регионом phone.defaultregion,
+// как при входе. Запускается один раз после миграции 20261019170000 и безопасен
+// при повторном запуске. Номера, которые не удалось привести, остаются как есть
+// и перечислены в phone_normalization_conflicts.
+//
+//	go run ./cmd/normalizephones
+func main() {
+	config.Init()
+
+	postgresql.InitPostgres()
+	defer postgresql.ClosePostgres()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	userRepo := userrepo.NewUserRepository(postgresql.DB)
+
+	updated, conflicts, err := userRepo.NormalizePhoneNumbers(ctx)
+	if err != nil {
+		log.Fatalf("failed to normalize phone numbers (updated %d, conflicts %d): %v", updated, conflicts, err)
+	}
+
+	log.Printf("normalized %d phone numbers, %d conflicts", updated, conflicts)
+	if conflicts > 0 {
+		log.Printf("review SELECT * FROM phone_normalization_conflicts: these users cannot sign in by phone until fixed")
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"log"
+	"strings"
+	"time"
+
+	"auth_service/internal/config"
+	"auth_service/internal/model/role"
+	"auth_service/internal/model/user"
+	rolerepo "auth_service/internal/repository/role"
+	userrepo "auth_service/internal/repository/user"
+	"auth_service/internal/storage/postgresql"
+	"auth_service/pkg/password"
+	"auth_service/pkg/phone"
+)
+
+// Создает первого суперадминистратора. Если пользователь с указанным
+// телефоном уже существует, ему назначается роль superadmin.
+//
+//	go run ./cmd/superadmin -phone "+7 916 123-45-67" -password secret -name Admin
+func main() {
+	phoneFlag := flag.String("phone", "", "phone number in international format")
+	pass := flag.String("password", "", "password for a new user")
+	name := flag.String("name", "Superadmin", "name for a new user")
+	email := flag.String("email", "", "email for a new user (optional)")
+	force := flag.Bool("force", false, "create even if a superadmin already exists")
+	flag.Parse()
+
+	config.Init()
+
+	phoneNumber, err := phone.Normalize(*phoneFlag, config.App.Phone.DefaultRegion)
+	if err != nil {
+		log.Fatalf("invalid phone number: %q", *phoneFlag)
+	}
+	postgresql.InitPostgres()
+	defer postgresql.ClosePostgres()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	userRepo := userrepo.NewUserRepository(postgresql.DB)
+	roleRepo := rolerepo.NewRoleRepository(postgresql.DB)
+
+	count, err := roleRepo.CountUsersWithRole(ctx, role.RoleSuperAdmin)
+	if err != nil {
+		log.Fatalf("failed to check existing superadmins: %v", err)
+	}
+	if count > 0 && !*force {
+		log.Fatalf("superadmin already exists, use -force to add another one")
+	}
+
+	u, err := userRepo.GetByPhoneNumber(ctx, phoneNumber)
+	if err != nil {
+		log.Fatalf("failed to look up user: %v", err)
+	}
+
+	if u == nil {
+		if len(*pass) < 6 {
+			log.Fatalf("password must be at least 6 characters")
+		}
+
+		hashedPassword, err := password.HashPassword(*pass)
+		if err != nil {
+			log.Fatalf("failed to hash password: %v", err)
+		}
+
+		u = &user.User{
+			Name:        strings.TrimSpace(*name),
+			PhoneNumber: phoneNumber,
+			Email:       sql.NullString{String: *email, Valid: *email != ""},
+			Password:    hashedPassword,
+		}
+
+		if err := userRepo.Create(ctx, u); err != nil {
+			log.Fatalf("failed to create user: %v", err)
+		}
+		log.Printf("created user %d", u.ID)
+	}
+
+	if err := roleRepo.AssignRole(ctx, u.ID, role.RoleSuperAdmin); err != nil {
+		log.Fatalf("failed to assign role: %v", err)
+	}
+
+	log.Printf("user %d (%s) is now %s", u.ID, u.PhoneNumber, role.RoleSuperAdmin)
+}
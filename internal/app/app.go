@@ -2,18 +2,43 @@ package app
 
 import (
 	"auth_service/internal/config"
+	"auth_service/internal/handler/admin_handler"
 	"auth_service/internal/handler/auth"
+	"auth_service/internal/handler/grpc_handler"
+	"auth_service/internal/handler/internal_handler"
 	"auth_service/internal/handler/profile_handler"
+	"auth_service/internal/handler/response"
 	"auth_service/internal/handler/router"
+	"auth_service/internal/handler/webhook_handler"
+	"auth_service/internal/model/user"
+	auditrepo "auth_service/internal/repository/audit"
+	exportrepo "auth_service/internal/repository/export"
+	otprepo "auth_service/internal/repository/otp"
+	outboxrepo "auth_service/internal/repository/outbox"
+	rolerepo "auth_service/internal/repository/role"
 	tokenrepo "auth_service/internal/repository/token"
 	userrepo "auth_service/internal/repository/user"
+	usercacherepo "auth_service/internal/repository/usercache"
+	webhookrepo "auth_service/internal/repository/webhook"
+	adminService "auth_service/internal/service/admin"
+	auditService "auth_service/internal/service/audit"
 	authService "auth_service/internal/service/auth"
+	deletionService "auth_service/internal/service/deletion"
+	exportService "auth_service/internal/service/export"
+	outboxService "auth_service/internal/service/outbox"
+	photoService "auth_service/internal/service/photo"
 	profileService "auth_service/internal/service/profile"
+	sessionService "auth_service/internal/service/session"
+	webhookService "auth_service/internal/service/webhook"
 	"auth_service/internal/storage"
+	"auth_service/internal/storage/blob"
 	"auth_service/internal/storage/postgresql"
 	"auth_service/internal/storage/redis"
+	"auth_service/pkg/requestinfo"
+	"auth_service/pkg/sms"
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
@@ -21,23 +46,142 @@ import (
 	"time"
 
 	httpSwagger "github.com/swaggo/http-swagger"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
 )
 
 func Run() {
 
+	if err := requestinfo.SetTrustedProxies(config.App.Server.TrustedProxies); err != nil {
+		log.Fatalf("Failed to parse trusted proxies: %v", err)
+	}
+
 	storage.BuildStorage()
 
+	blobStore, err := blob.New()
+	if err != nil {
+		log.Fatalf("Failed to init blob storage: %v", err)
+	}
+
+	photoURLTTL, err := time.ParseDuration(config.App.Photo.URLTTL)
+	if err != nil {
+		photoURLTTL = time.Hour
+	}
+	user.SetPhotoURLSigner(func(objectKey string) string {
+		photoURL, err := blobStore.PresignGet(context.Background(), objectKey, photoURLTTL)
+		if err != nil {
+			log.Printf("failed to presign photo %s: %v", objectKey, err)
+			return ""
+		}
+		return photoURL
+	})
+
 	userRepo := userrepo.NewUserRepository(postgresql.DB)
 	tokenRepo := tokenrepo.NewTokenRepository(redis.RedisClient)
+	roleRepo := rolerepo.NewRoleRepository(postgresql.DB)
+	auditRepo := auditrepo.NewAuditRepository(postgresql.DB)
+	otpRepo := otprepo.NewOTPRepository(redis.RedisClient)
+	exportRepo := exportrepo.NewExportRepository(redis.RedisClient)
+	outboxRepo := outboxrepo.NewOutboxRepository(postgresql.DB)
+	webhookRepo := webhookrepo.NewWebhookRepository(postgresql.DB)
+	userCache := usercacherepo.NewUserCacheRepository(redis.RedisClient, parseDurationOr(config.App.Internal.UserCacheTTL, 5*time.Minute))
+
+	smsSender, err := sms.New(config.App.SMS.Provider)
+	if err != nil {
+		log.Fatalf("Failed to init sms sender: %v", err)
+	}
+
+	// Создается до profileService, который перекрывает имя пакета.
+	// Кэш карточек для GetUsersByIDs сбрасывается по событиям пользователя из outbox.
+	userCacheSink := profileService.NewCacheInvalidationSink(userCache)
+
+	auditService := auditService.NewAuditService(auditRepo)
+	sessionService := sessionService.NewSessionService(userRepo, tokenRepo)
+	authService := authService.NewAuthService(userRepo, tokenRepo, roleRepo, otpRepo, sessionService, smsSender, auditService)
+	profileService := profileService.NewProfileService(userRepo, userCache, sessionService, blobStore, auditService)
+	adminService := adminService.NewAdminService(userRepo, roleRepo, sessionService, auditService)
+	exportService := exportService.NewExportService(userRepo, roleRepo, exportRepo, blobStore, auditService)
+
+	workerCtx, stopWorkers := context.WithCancel(context.Background())
+	defer stopWorkers()
 
-	authService := authService.NewAuthService(userRepo, tokenRepo)
-	profileService := profileService.NewProfileService(userRepo, tokenRepo)
+	// photo.gcinterval = 0 отключает сборку мусора, например на второй реплике
+	gcInterval, err := time.ParseDuration(config.App.Photo.GCInterval)
+	if err == nil && gcInterval > 0 {
+		gcGracePeriod, err := time.ParseDuration(config.App.Photo.GCGracePeriod)
+		if err != nil {
+			gcGracePeriod = 24 * time.Hour
+		}
+		photoGC := photoService.NewPhotoGCService(userRepo, blobStore, gcGracePeriod)
+		go photoGC.Run(workerCtx, gcInterval)
+	}
+
+	go exportService.Run(workerCtx)
+
+	// deletion.purgeinterval = 0 отключает обезличивание, как и photo.gcinterval
+	purgeInterval, err := time.ParseDuration(config.App.Deletion.PurgeInterval)
+	if err == nil && purgeInterval > 0 {
+		gracePeriod, err := time.ParseDuration(config.App.Deletion.GracePeriod)
+		if err != nil {
+			gracePeriod = 30 * 24 * time.Hour
+		}
+		accountPurge := deletionService.NewAccountPurgeService(userRepo, tokenRepo, otpRepo, exportRepo, blobStore, auditService, gracePeriod)
+		go accountPurge.Run(workerCtx, purgeInterval)
+	}
+
+	outboxSinks, err := outboxService.NewSinks(config.App.Outbox.Sinks, redis.RedisClient)
+	if err != nil {
+		log.Fatalf("Failed to init outbox sinks: %v", err)
+	}
+	// Зарегистрированные через API webhook получают события всегда, независимо от outbox.sinks
+	outboxSinks = append(outboxSinks, webhookService.NewFanoutSink(webhookRepo))
+	outboxSinks = append(outboxSinks, userCacheSink)
+	// outbox.pollinterval = 0 отключает relay, события копятся в таблице до включения
+	outboxInterval, err := time.ParseDuration(config.App.Outbox.PollInterval)
+	if err == nil && outboxInterval > 0 {
+		outboxCfg := config.App.Outbox
+		relay := outboxService.NewRelay(
+			outboxRepo,
+			outboxSinks,
+			outboxCfg.BatchSize,
+			parseDurationOr(outboxCfg.Lease, 30*time.Second),
+			parseDurationOr(outboxCfg.RetryDelay, time.Second),
+			parseDurationOr(outboxCfg.Retention, 7*24*time.Hour),
+		)
+		go relay.Run(workerCtx, outboxInterval)
+	}
+
+	webhookCfg := config.App.Webhooks
+	dispatchInterval, err := time.ParseDuration(webhookCfg.DispatchInterval)
+	if err == nil && dispatchInterval > 0 {
+		dispatcher := webhookService.NewDispatcher(
+			webhookRepo,
+			webhookService.NewHTTPClient(parseDurationOr(webhookCfg.Timeout, 10*time.Second)),
+			webhookService.DispatcherConfig{
+				BatchSize:     webhookCfg.BatchSize,
+				Concurrency:   webhookCfg.Concurrency,
+				Lease:         parseDurationOr(webhookCfg.Lease, 2*time.Minute),
+				MaxAttempts:   webhookCfg.MaxAttempts,
+				RetryDelay:    parseDurationOr(webhookCfg.RetryDelay, 30*time.Second),
+				MaxRetryDelay: parseDurationOr(webhookCfg.MaxRetryDelay, 6*time.Hour),
+				Retention:     parseDurationOr(webhookCfg.Retention, 30*24*time.Hour),
+			},
+		)
+		go dispatcher.Run(workerCtx, dispatchInterval)
+	}
 
 	authHandler := auth.NewAuthHandler(authService)
-	profileHandler := profile_handler.NewProfileHandler(profileService)
+	profileHandler := profile_handler.NewProfileHandler(profileService, authService, exportService)
+	adminHandler := admin_handler.NewAdminHandler(adminService)
+	webhookService := webhookService.NewWebhookService(webhookRepo, auditService)
+	webhookHandler := webhook_handler.NewWebhookHandler(webhookService)
+	internalHandler := internal_handler.NewInternalHandler(profileService)
 
-	router := router.SetupRouter(authHandler, profileHandler, userRepo, tokenRepo)
+	router := router.SetupRouter(authHandler, profileHandler, adminHandler, webhookHandler, internalHandler, userRepo, tokenRepo, roleRepo)
 	router.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
+	if prefix, handler, ok := blob.LocalHandler(blobStore, response.Error); ok {
+		router.PathPrefix(prefix).Handler(handler)
+	}
 	server := &http.Server{
 		Addr:         ":" + config.App.Server.Port,
 		Handler:      router,
@@ -53,18 +197,66 @@ func Run() {
 		}
 	}()
 
+	var grpcServer *grpc.Server
+	var grpcHealth *health.Server
+	if config.App.GRPC.Port != "" {
+		listener, err := net.Listen("tcp", ":"+config.App.GRPC.Port)
+		if err != nil {
+			log.Fatalf("Failed to listen gRPC port: %v", err)
+		}
+
+		grpcServer, grpcHealth = grpc_handler.NewServer(authService, profileService, userRepo, tokenRepo, config.App.Internal.ServiceTokens)
+		go func() {
+			log.Printf("gRPC server starting on port %s", config.App.GRPC.Port)
+			if err := grpcServer.Serve(listener); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	log.Println("Shutting down server...")
 
-	postgresql.ClosePostgres()
-	redis.CloseRedis()
-
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
+	// Сначала дожидаемся начатых запросов, и только потом закрываем хранилища
+	if grpcServer != nil {
+		grpcHealth.Shutdown()
+		stopGRPC(ctx, grpcServer)
+	}
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatalf("Server forced to shutdown: %v", err)
+		log.Printf("Server forced to shutdown: %v", err)
+	}
+
+	stopWorkers()
+
+	postgresql.ClosePostgres()
+	redis.CloseRedis()
+}
+
+// stopGRPC дает активным вызовам завершиться, а по истечении ctx обрывает их
+func stopGRPC(ctx context.Context, server *grpc.Server) {
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		log.Println("gRPC server forced to stop")
+		server.Stop()
+	}
+}
+
+func parseDurationOr(durationStr string, fallback time.Duration) time.Duration {
+	dur, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return fallback
 	}
+	return dur
 }
@@ -14,9 +14,16 @@ import (
 
 type Config struct {
 	Server struct {
-		Port string `mapstructure:"port"`
+		Port            string `mapstructure:"port"`
+		ProblemTypeBase string `mapstructure:"problemtypebase"`
+		// TrustedProxies — CIDR прокси, чьим X-Forwarded-For и X-Real-IP можно верить
+		TrustedProxies []string `mapstructure:"trustedproxies"`
 	} `mapstructure:"server"`
 
+	GRPC struct {
+		Port string `mapstructure:"port"`
+	} `mapstructure:"grpc"`
+
 	Postgres struct {
 		Host     string `mapstructure:"host"`
 		Port     string `mapstructure:"port"`
@@ -33,10 +40,11 @@ type Config struct {
 	} `mapstructure:"redis"`
 
 	JWT struct {
-		AccessSecret  string `mapstructure:"accesssecret"`
-		RefreshSecret string `mapstructure:"refreshsecret"`
-		AccessTTL     string `mapstructure:"accessttl"`
-		RefreshTTL    string `mapstructure:"refreshttl"`
+		AccessSecret     string `mapstructure:"accesssecret"`
+		RefreshSecret    string `mapstructure:"refreshsecret"`
+		AccessTTL        string `mapstructure:"accessttl"`
+		RefreshTTL       string `mapstructure:"refreshttl"`
+		ImpersonationTTL string `mapstructure:"impersonationttl"`
 	} `mapstructure:"jwt"`
 
 	Minio struct {
@@ -46,7 +54,87 @@ type Config struct {
 		Bucket    string `mapstructure:"bucket"`
 		UseSSL    bool   `mapstructure:"usessl"`
 		Domain    string `mapstructure:"domain"`
+		Region    string `mapstructure:"region"`
 	} `mapstructure:"minio"`
+
+	Phone struct {
+		DefaultRegion string `mapstructure:"defaultregion"`
+	} `mapstructure:"phone"`
+
+	I18n struct {
+		DefaultLocale string `mapstructure:"defaultlocale"`
+	} `mapstructure:"i18n"`
+
+	OTP struct {
+		TTL            string `mapstructure:"ttl"`
+		Length         int    `mapstructure:"length"`
+		MaxAttempts    int    `mapstructure:"maxattempts"`
+		ResendInterval string `mapstructure:"resendinterval"`
+	} `mapstructure:"otp"`
+
+	SMS struct {
+		Provider string `mapstructure:"provider"`
+	} `mapstructure:"sms"`
+
+	Storage struct {
+		Backend    string `mapstructure:"backend"`
+		Path       string `mapstructure:"path"`
+		PublicURL  string `mapstructure:"publicurl"`
+		SigningKey string `mapstructure:"signingkey"`
+	} `mapstructure:"storage"`
+
+	Photo struct {
+		MaxBytes       int64  `mapstructure:"maxbytes"`
+		MinDimension   int    `mapstructure:"mindimension"`
+		MaxDimension   int    `mapstructure:"maxdimension"`
+		ThumbnailSizes []int  `mapstructure:"thumbnailsizes"`
+		UploadURLTTL   string `mapstructure:"uploadurlttl"`
+		URLTTL         string `mapstructure:"urlttl"`
+		GCInterval     string `mapstructure:"gcinterval"`
+		GCGracePeriod  string `mapstructure:"gcgraceperiod"`
+	} `mapstructure:"photo"`
+
+	Deletion struct {
+		GracePeriod   string `mapstructure:"graceperiod"`
+		PurgeInterval string `mapstructure:"purgeinterval"`
+	} `mapstructure:"deletion"`
+
+	Export struct {
+		Workers   int    `mapstructure:"workers"`
+		QueueSize int    `mapstructure:"queuesize"`
+		Retention string `mapstructure:"retention"`
+		URLTTL    string `mapstructure:"urlttl"`
+	} `mapstructure:"export"`
+
+	Outbox struct {
+		Sinks             []string `mapstructure:"sinks"`
+		PollInterval      string   `mapstructure:"pollinterval"`
+		BatchSize         int      `mapstructure:"batchsize"`
+		Lease             string   `mapstructure:"lease"`
+		RetryDelay        string   `mapstructure:"retrydelay"`
+		Retention         string   `mapstructure:"retention"`
+		RedisStream       string   `mapstructure:"redisstream"`
+		RedisStreamMaxLen int64    `mapstructure:"redisstreammaxlen"`
+		WebhookURL        string   `mapstructure:"webhookurl"`
+		WebhookTimeout    string   `mapstructure:"webhooktimeout"`
+	} `mapstructure:"outbox"`
+
+	Webhooks struct {
+		DispatchInterval string `mapstructure:"dispatchinterval"`
+		BatchSize        int    `mapstructure:"batchsize"`
+		Concurrency      int    `mapstructure:"concurrency"`
+		Lease            string `mapstructure:"lease"`
+		Timeout          string `mapstructure:"timeout"`
+		MaxAttempts      int    `mapstructure:"maxattempts"`
+		RetryDelay       string `mapstructure:"retrydelay"`
+		MaxRetryDelay    string `mapstructure:"maxretrydelay"`
+		Retention        string `mapstructure:"retention"`
+	} `mapstructure:"webhooks"`
+
+	Internal struct {
+		ServiceTokens []string `mapstructure:"servicetokens"`
+		UserCacheTTL  string   `mapstructure:"usercachettl"`
+	} `mapstructure:"internal"`
 }
 
 var App Config
@@ -70,6 +158,11 @@ func Init() {
 	v := viper.New()
 
 	v.SetDefault("server.port", "8080")
+	v.SetDefault("server.problemtypebase", "urn:auth-service:problem:")
+	v.SetDefault("server.trustedproxies", []string{})
+
+	// grpc.port = "" отключает gRPC сервер
+	v.SetDefault("grpc.port", "9090")
 
 	v.SetDefault("postgres.host", "localhost")
 	v.SetDefault("postgres.port", "5432")
@@ -86,6 +179,7 @@ func Init() {
 	v.SetDefault("jwt.refreshsecret", "change_me")
 	v.SetDefault("jwt.accessttl", "15m")
 	v.SetDefault("jwt.refreshttl", "720h")
+	v.SetDefault("jwt.impersonationttl", "10m")
 
 	v.SetDefault("minio.endpoint", "localhost:9000")
 	v.SetDefault("minio.accesskey", "minioadmin")
@@ -93,6 +187,65 @@ func Init() {
 	v.SetDefault("minio.bucket", "user-photos")
 	v.SetDefault("minio.usessl", false)
 	v.SetDefault("minio.domain", "localhost:9000")
+	v.SetDefault("minio.region", "us-east-1")
+
+	v.SetDefault("phone.defaultregion", "RU")
+
+	v.SetDefault("i18n.defaultlocale", "en")
+
+	v.SetDefault("otp.ttl", "10m")
+	v.SetDefault("otp.length", 6)
+	v.SetDefault("otp.maxattempts", 5)
+	v.SetDefault("otp.resendinterval", "1m")
+
+	v.SetDefault("sms.provider", "log")
+
+	v.SetDefault("storage.backend", "minio")
+	v.SetDefault("storage.path", "./data/blobs")
+	v.SetDefault("storage.publicurl", "http://localhost:8080/blobs")
+	v.SetDefault("storage.signingkey", "change_me")
+
+	v.SetDefault("photo.maxbytes", 10<<20)
+	v.SetDefault("photo.mindimension", 32)
+	v.SetDefault("photo.maxdimension", 8192)
+	v.SetDefault("photo.thumbnailsizes", []int{64, 256, 1024})
+	v.SetDefault("photo.uploadurlttl", "15m")
+	v.SetDefault("photo.urlttl", "1h")
+	v.SetDefault("photo.gcinterval", "6h")
+	v.SetDefault("photo.gcgraceperiod", "24h")
+
+	v.SetDefault("deletion.graceperiod", "720h")
+	v.SetDefault("deletion.purgeinterval", "1h")
+
+	v.SetDefault("export.workers", 2)
+	v.SetDefault("export.queuesize", 100)
+	v.SetDefault("export.retention", "24h")
+	v.SetDefault("export.urlttl", "15m")
+
+	v.SetDefault("outbox.sinks", []string{})
+	v.SetDefault("outbox.pollinterval", "1s")
+	v.SetDefault("outbox.batchsize", 100)
+	v.SetDefault("outbox.lease", "30s")
+	v.SetDefault("outbox.retrydelay", "1s")
+	v.SetDefault("outbox.retention", "168h")
+	v.SetDefault("outbox.redisstream", "user-events")
+	v.SetDefault("outbox.redisstreammaxlen", 100000)
+	v.SetDefault("outbox.webhookurl", "")
+	v.SetDefault("outbox.webhooktimeout", "5s")
+
+	v.SetDefault("webhooks.dispatchinterval", "1s")
+	v.SetDefault("webhooks.batchsize", 50)
+	v.SetDefault("webhooks.concurrency", 8)
+	v.SetDefault("webhooks.lease", "2m")
+	v.SetDefault("webhooks.timeout", "10s")
+	v.SetDefault("webhooks.maxattempts", 10)
+	v.SetDefault("webhooks.retrydelay", "30s")
+	v.SetDefault("webhooks.maxretrydelay", "6h")
+	v.SetDefault("webhooks.retention", "720h")
+
+	// Пустой internal.servicetokens закрывает внутренний API
+	v.SetDefault("internal.servicetokens", []string{})
+	v.SetDefault("internal.usercachettl", "5m")
 
 	v.SetEnvKeyReplacer(strings.NewReplacer(".", "_"))
 
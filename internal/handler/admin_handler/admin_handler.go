@@ -0,0 +1,619 @@
+package admin_handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"auth_service/internal/handler/response"
+	"auth_service/internal/middleware"
+	"auth_service/internal/model/audit"
+	"auth_service/internal/model/request"
+	"auth_service/internal/model/responce"
+	"auth_service/internal/model/user"
+	adminService "auth_service/internal/service/admin"
+	"auth_service/pkg/apperror"
+
+	"github.com/gorilla/mux"
+)
+
+type Admin_Handler interface {
+	ListRoles(w http.ResponseWriter, r *http.Request)
+	GetUserRoles(w http.ResponseWriter, r *http.Request)
+	AssignRole(w http.ResponseWriter, r *http.Request)
+	RevokeRole(w http.ResponseWriter, r *http.Request)
+	ListUsers(w http.ResponseWriter, r *http.Request)
+	GetUser(w http.ResponseWriter, r *http.Request)
+	UpdateUser(w http.ResponseWriter, r *http.Request)
+	ResetPassword(w http.ResponseWriter, r *http.Request)
+	BlockUser(w http.ResponseWriter, r *http.Request)
+	UnblockUser(w http.ResponseWriter, r *http.Request)
+	RestoreUser(w http.ResponseWriter, r *http.Request)
+	RevokeSessions(w http.ResponseWriter, r *http.Request)
+	QueryAudit(w http.ResponseWriter, r *http.Request)
+	Impersonate(w http.ResponseWriter, r *http.Request)
+}
+
+type AdminHandler struct {
+	adminService *adminService.AdminService
+}
+
+func NewAdminHandler(adminService *adminService.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// ListRoles
+// @Summary Список ролей
+// @Description Возвращает все роли системы
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Router /api/v1/admin/roles [get]
+func (h *AdminHandler) ListRoles(w http.ResponseWriter, r *http.Request) {
+	roles, err := h.adminService.ListRoles(r.Context())
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    roles,
+	}, http.StatusOK)
+}
+
+// GetUserRoles
+// @Summary Роли пользователя
+// @Description Возвращает роли, назначенные пользователю
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/admin/users/{id}/roles [get]
+func (h *AdminHandler) GetUserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	roles, err := h.adminService.GetUserRoles(r.Context(), userID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    roles,
+	}, http.StatusOK)
+}
+
+// AssignRole
+// @Summary Назначение роли
+// @Description Назначает пользователю роль и завершает его сессии: новые права действуют после повторного входа
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param request body request.AssignRoleRequest true "Роль"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/admin/users/{id}/roles [post]
+func (h *AdminHandler) AssignRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.AssignRoleRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.adminService.AssignRole(r.Context(), actorID, userID, req.Role); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"message": "role assigned",
+	}, http.StatusOK)
+}
+
+// RevokeRole
+// @Summary Отзыв роли
+// @Description Снимает с пользователя роль и завершает его сессии, чтобы права не оставались в выданных токенах
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param role path string true "Название роли"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Router /api/v1/admin/users/{id}/roles/{role} [delete]
+func (h *AdminHandler) RevokeRole(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.adminService.RevokeRole(r.Context(), actorID, userID, mux.Vars(r)["role"]); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"message": "role revoked",
+	}, http.StatusOK)
+}
+
+// ListUsers
+// @Summary Список пользователей
+// @Description Постраничный список пользователей с фильтрами
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param phone query string false "Часть номера телефона"
+// @Param email query string false "Часть email"
+// @Param name query string false "Часть имени"
+// @Param created_from query string false "Создан не раньше (RFC3339 или YYYY-MM-DD)"
+// @Param created_to query string false "Создан раньше (RFC3339 или YYYY-MM-DD)"
+// @Param status query string false "Статус аккаунта" Enums(active, suspended, banned, pending_verification)
+// @Param deleted query bool false "Фильтр по признаку удаления"
+// @Param page query int false "Номер страницы" default(1)
+// @Param page_size query int false "Размер страницы" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Router /api/v1/admin/users [get]
+func (h *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, pageSize, err := response.ParsePagination(r)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	filter := user.ListFilter{
+		PhoneNumber: query.Get("phone"),
+		Email:       query.Get("email"),
+		Name:        query.Get("name"),
+		Limit:       pageSize,
+		Offset:      (page - 1) * pageSize,
+	}
+
+	if filter.CreatedFrom, err = parseTime(query.Get("created_from")); err != nil {
+		response.Error(w, r, apperror.InvalidParameter("created_from"))
+		return
+	}
+	if filter.CreatedTo, err = parseTime(query.Get("created_to")); err != nil {
+		response.Error(w, r, apperror.InvalidParameter("created_to"))
+		return
+	}
+
+	if filter.Status = query.Get("status"); filter.Status != "" && !user.IsValidStatus(filter.Status) {
+		response.Error(w, r, apperror.InvalidParameter("status"))
+		return
+	}
+
+	if deleted := query.Get("deleted"); deleted != "" {
+		value, err := strconv.ParseBool(deleted)
+		if err != nil {
+			response.Error(w, r, apperror.InvalidParameter("deleted"))
+			return
+		}
+		filter.IsDeleted = &value
+	}
+
+	users, total, err := h.adminService.ListUsers(r.Context(), filter)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	list := responce.UserListResponse{
+		Users:    make([]responce.AdminUserResponse, 0, len(users)),
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for i := range users {
+		list.Users = append(list.Users, users[i].ToAdminResponse())
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    list,
+	}, http.StatusOK)
+}
+
+// GetUser
+// @Summary Получение пользователя
+// @Description Возвращает пользователя, включая удаленных
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/admin/users/{id} [get]
+func (h *AdminHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	user, err := h.adminService.GetUser(r.Context(), userID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    user.ToAdminResponse(),
+	}, http.StatusOK)
+}
+
+// UpdateUser
+// @Summary Изменение пользователя
+// @Description Изменяет имя, email или телефон пользователя. Недоступно для superadmin и пользователей с ролями, которых нет у сотрудника
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param request body request.AdminUpdateUserRequest true "Новые данные"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/admin/users/{id} [put]
+func (h *AdminHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.AdminUpdateUserRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	user, err := h.adminService.UpdateUser(r.Context(), actorID, userID, req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    user.ToAdminResponse(),
+		"message": "user updated successfully",
+	}, http.StatusOK)
+}
+
+// ResetPassword
+// @Summary Принудительный сброс пароля
+// @Description Устанавливает временный пароль и завершает все сессии пользователя. Недоступно для superadmin и пользователей с ролями, которых нет у сотрудника
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/admin/users/{id}/password-reset [post]
+func (h *AdminHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	temporary, err := h.adminService.ResetPassword(r.Context(), actorID, userID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data": map[string]string{
+			"temporary_password": temporary,
+		},
+		"message": "password reset successfully",
+	}, http.StatusOK)
+}
+
+// BlockUser
+// @Summary Блокировка пользователя
+// @Description Приостанавливает (на срок или бессрочно) или банит пользователя и завершает его сессии. Недоступно для superadmin и пользователей с ролями, которых нет у сотрудника
+// @Tags Admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param request body request.BlockUserRequest false "Статус, причина и срок блокировки"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/admin/users/{id}/block [post]
+func (h *AdminHandler) BlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	var req request.BlockUserRequest
+	if r.ContentLength != 0 {
+		if !response.DecodeJSON(w, r, &req) {
+			return
+		}
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.adminService.BlockUser(r.Context(), actorID, userID, req); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"message": "user blocked",
+	}, http.StatusOK)
+}
+
+// UnblockUser
+// @Summary Разблокировка пользователя
+// @Description Снимает блокировку с пользователя
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Router /api/v1/admin/users/{id}/unblock [post]
+func (h *AdminHandler) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.adminService.UnblockUser(r.Context(), actorID, userID); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"message": "user unblocked",
+	}, http.StatusOK)
+}
+
+// RestoreUser
+// @Summary Восстановление пользователя
+// @Description Восстанавливает мягко удаленного пользователя
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Router /api/v1/admin/users/{id}/restore [post]
+func (h *AdminHandler) RestoreUser(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	user, err := h.adminService.RestoreUser(r.Context(), actorID, userID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    user.ToAdminResponse(),
+		"message": "user restored",
+	}, http.StatusOK)
+}
+
+// RevokeSessions
+// @Summary Завершение всех сессий
+// @Description Отзывает refresh токен и все выданные access токены пользователя
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/admin/users/{id}/sessions [delete]
+func (h *AdminHandler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.adminService.RevokeSessions(r.Context(), actorID, userID); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"message": "sessions revoked",
+	}, http.StatusOK)
+}
+
+// QueryAudit
+// @Summary Журнал аудита
+// @Description Выборка событий аудита с фильтрами
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param actor_id query int false "Кто выполнил действие"
+// @Param subject_id query int false "Над кем выполнено действие"
+// @Param action query string false "Тип действия"
+// @Param from query string false "Не раньше (RFC3339 или YYYY-MM-DD)"
+// @Param to query string false "Раньше (RFC3339 или YYYY-MM-DD)"
+// @Param page query int false "Номер страницы" default(1)
+// @Param page_size query int false "Размер страницы" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Router /api/v1/admin/audit [get]
+func (h *AdminHandler) QueryAudit(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	page, pageSize, err := response.ParsePagination(r)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	filter := audit.Filter{
+		Action: query.Get("action"),
+		Limit:  pageSize,
+		Offset: (page - 1) * pageSize,
+	}
+
+	if filter.ActorID, err = parseID(query.Get("actor_id")); err != nil {
+		response.Error(w, r, apperror.InvalidParameter("actor_id"))
+		return
+	}
+	if filter.SubjectID, err = parseID(query.Get("subject_id")); err != nil {
+		response.Error(w, r, apperror.InvalidParameter("subject_id"))
+		return
+	}
+	if filter.From, err = parseTime(query.Get("from")); err != nil {
+		response.Error(w, r, apperror.InvalidParameter("from"))
+		return
+	}
+	if filter.To, err = parseTime(query.Get("to")); err != nil {
+		response.Error(w, r, apperror.InvalidParameter("to"))
+		return
+	}
+
+	events, total, err := h.adminService.QueryAudit(r.Context(), filter)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data": responce.AuditEventListResponse{
+			Events:   events,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	}, http.StatusOK)
+}
+
+// Impersonate
+// @Summary Вход от имени пользователя
+// @Description Выдает короткоживущий access токен пользователя с claim act (RFC 8693). Refresh токен не выдается, смена учетных данных и удаление профиля запрещены
+// @Tags Admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Router /api/v1/admin/users/{id}/impersonate [post]
+func (h *AdminHandler) Impersonate(w http.ResponseWriter, r *http.Request) {
+	userID, ok := pathUserID(w, r)
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	token, expiresAt, err := h.adminService.Impersonate(r.Context(), actorID, userID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"access_token": token,
+			"token_type":   "Bearer",
+			"expires_at":   expiresAt,
+		},
+		"message": "impersonation token issued",
+	}, http.StatusOK)
+}
+
+func parseID(value string) (*int64, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, strconv.ErrSyntax
+	}
+
+	return &id, nil
+}
+
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t, nil
+		}
+	}
+
+	return nil, strconv.ErrSyntax
+}
+
+func pathUserID(w http.ResponseWriter, r *http.Request) (int64, bool) {
+	userID, err := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	if err != nil || userID <= 0 {
+		response.Error(w, r, apperror.InvalidParameter("id"))
+		return 0, false
+	}
+
+	return userID, true
+}
@@ -1,19 +1,24 @@
 package auth
 
 import (
-	"encoding/json"
 	"net/http"
 	"strings"
 
+	"auth_service/internal/handler/response"
+	"auth_service/internal/middleware"
 	"auth_service/internal/model/request"
 	authService "auth_service/internal/service/auth"
+	"auth_service/pkg/apperror"
 )
 
 type Auth_handler interface {
 	SignUp(w http.ResponseWriter, r *http.Request)
 	SignIn(w http.ResponseWriter, r *http.Request)
 	Logout(w http.ResponseWriter, r *http.Request)
+	LogoutAll(w http.ResponseWriter, r *http.Request)
 	Refresh(w http.ResponseWriter, r *http.Request)
+	RequestRestore(w http.ResponseWriter, r *http.Request)
+	ConfirmRestore(w http.ResponseWriter, r *http.Request)
 }
 
 type AuthHandler struct {
@@ -32,25 +37,25 @@ func NewAuthHandler(authService *authService.AuthService) *AuthHandler {
 // @Produce json
 // @Param request body db.SignUpRequest true "Данные для регистрации"
 // @Success 201 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
 // @Router /api/v1/auth/signup [post]
 
 func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 	var req request.SignUpRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !response.DecodeJSON(w, r, &req) {
 		return
 	}
 
 	ctx := r.Context()
 	user, tokens, err := h.authService.SignUp(ctx, req)
 	if err != nil {
-		ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
+	resp := map[string]interface{}{
 		"success": true,
 		"data": map[string]interface{}{
 			"user": map[string]interface{}{
@@ -58,7 +63,7 @@ func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 				"name":         user.Name,
 				"phone_number": user.PhoneNumber,
 				"email":        user.Email.String,
-				"photo_url":    user.PhotoURL.String,
+				"photo_url":    user.ToResponse().PhotoURL,
 				"created_at":   user.CreatedAt,
 			},
 			"tokens": tokens,
@@ -66,7 +71,7 @@ func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 		"message": "registration successful",
 	}
 
-	JsonResponse(w, response, http.StatusCreated)
+	response.JSON(w, resp, http.StatusCreated)
 }
 
 // SignIn
@@ -77,24 +82,25 @@ func (h *AuthHandler) SignUp(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param request body db.LoginRequest true "Учетные данные"
 // @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
+// @Failure 403 {object} responce.Problem "account_deleted — аккаунт можно восстановить через /auth/restore"
+// @Failure 422 {object} responce.Problem
 // @Router /api/v1/auth/signin [post]
 func (h *AuthHandler) SignIn(w http.ResponseWriter, r *http.Request) {
 	var req request.LoginRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !response.DecodeJSON(w, r, &req) {
 		return
 	}
 
 	ctx := r.Context()
 	user, tokens, err := h.authService.SignIn(ctx, req)
 	if err != nil {
-		ErrorResponse(w, "Invalid credentials", http.StatusUnauthorized)
+		response.Error(w, r, err)
 		return
 	}
 
-	response := map[string]interface{}{
+	resp := map[string]interface{}{
 		"success": true,
 		"data": map[string]interface{}{
 			"user": map[string]interface{}{
@@ -102,14 +108,14 @@ func (h *AuthHandler) SignIn(w http.ResponseWriter, r *http.Request) {
 				"name":         user.Name,
 				"phone_number": user.PhoneNumber,
 				"email":        user.Email.String,
-				"photo_url":    user.PhotoURL.String,
+				"photo_url":    user.ToResponse().PhotoURL,
 			},
 			"tokens": tokens,
 		},
 		"message": "login successful",
 	}
 
-	JsonResponse(w, response, http.StatusOK)
+	response.JSON(w, resp, http.StatusOK)
 }
 
 // Logout
@@ -120,31 +126,53 @@ func (h *AuthHandler) SignIn(w http.ResponseWriter, r *http.Request) {
 // @Accept json
 // @Produce json
 // @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
 // @Router /api/v1/profile/logout [post]
 func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
-	userID, _ := r.Context().Value("user_id").(int64)
-	/*if !ok {
-		errorResponse(w, "unauthorized", http.StatusUnauthorized)
-		return
-	}*/
-
 	authHeader := r.Header.Get("Authorization")
 	token := strings.TrimPrefix(authHeader, "Bearer ")
 
 	ctx := r.Context()
-	err := h.authService.Logout(ctx, userID, token)
+	err := h.authService.Logout(ctx, token)
 	if err != nil {
-		ErrorResponse(w, "Logout failed", http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
-	JsonResponse(w, map[string]interface{}{
+	response.JSON(w, map[string]interface{}{
 		"success": true,
 		"message": "logged out successfully",
 	}, http.StatusOK)
 }
 
+// LogoutAll
+// @Summary Выход на всех устройствах
+// @Description Отзывает refresh токен и все выданные access токены пользователя, включая текущий
+// @Tags Authentication
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Router /api/v1/profile/logout-all [post]
+func (h *AuthHandler) LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	if err := h.authService.LogoutAll(r.Context(), userID); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"message": "all sessions revoked",
+	}, http.StatusOK)
+}
+
 // Refresh
 // @Summary Обновление токенов
 // @Description Получение новой пары токенов
@@ -153,43 +181,100 @@ func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param request body db.RefreshTokenRequest true "Refresh токен"
 // @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
 // @Router /api/v1/auth/refresh [post]
 func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
-	var req struct {
-		RefreshToken string `json:"refresh_token"`
-	}
+	var req request.RefreshTokenRequest
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	if !response.DecodeJSON(w, r, &req) {
 		return
 	}
 
 	ctx := r.Context()
 	tokens, err := h.authService.RefreshTokens(ctx, req.RefreshToken)
 	if err != nil {
-		ErrorResponse(w, "invalid or expired refresh token", http.StatusUnauthorized)
+		response.Error(w, r, err)
 		return
 	}
 
-	JsonResponse(w, map[string]interface{}{
+	response.JSON(w, map[string]interface{}{
 		"success": true,
 		"data":    tokens,
 		"message": "tokens refreshed",
 	}, http.StatusOK)
 }
 
-func ErrorResponse(w http.ResponseWriter, message string, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": false,
-		"error":   message,
-	})
+// RequestRestore
+// @Summary Запрос восстановления удаленного аккаунта
+// @Description Проверяет пароль аккаунта, удаленного в течение льготного периода, и отправляет код подтверждения на его номер
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body request.RestoreAccountRequest true "Номер телефона и пароль"
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Failure 429 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/auth/restore [post]
+func (h *AuthHandler) RequestRestore(w http.ResponseWriter, r *http.Request) {
+	var req request.RestoreAccountRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	expiresAt, err := h.authService.RequestAccountRestore(r.Context(), req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"expires_at": expiresAt,
+		},
+		"message": "verification code sent",
+	}, http.StatusOK)
 }
 
-func JsonResponse(w http.ResponseWriter, data interface{}, statusCode int) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(statusCode)
-	json.NewEncoder(w).Encode(data)
+// ConfirmRestore
+// @Summary Подтверждение восстановления аккаунта
+// @Description Проверяет код из SMS, снимает пометку об удалении и выдает новую пару токенов
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body request.ConfirmAccountRestoreRequest true "Номер телефона и код из SMS"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 429 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/auth/restore/confirm [post]
+func (h *AuthHandler) ConfirmRestore(w http.ResponseWriter, r *http.Request) {
+	var req request.ConfirmAccountRestoreRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	user, tokens, err := h.authService.ConfirmAccountRestore(r.Context(), req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"user": map[string]interface{}{
+				"id":           user.ID,
+				"name":         user.Name,
+				"phone_number": user.PhoneNumber,
+				"email":        user.Email.String,
+				"photo_url":    user.ToResponse().PhotoURL,
+			},
+			"tokens": tokens,
+		},
+		"message": "account restored",
+	}, http.StatusOK)
 }
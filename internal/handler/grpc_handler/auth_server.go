@@ -0,0 +1,118 @@
+package grpc_handler
+
+import (
+	"context"
+
+	"auth_service/internal/model/request"
+	tokenrepo "auth_service/internal/repository/token"
+	userrepo "auth_service/internal/repository/user"
+	authService "auth_service/internal/service/auth"
+	"auth_service/pkg/api/authv1"
+	"auth_service/pkg/requestinfo"
+	"auth_service/pkg/validation"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+type AuthServer struct {
+	authv1.UnimplementedAuthServiceServer
+
+	authService *authService.AuthService
+	auth        *authenticator
+}
+
+func NewAuthServer(authService *authService.AuthService, userRepo *userrepo.UserRepository, tokenRepo *tokenrepo.TokenRepository) *AuthServer {
+	return &AuthServer{
+		authService: authService,
+		auth:        &authenticator{userRepo: userRepo, tokenRepo: tokenRepo},
+	}
+}
+
+func (s *AuthServer) SignUp(ctx context.Context, req *authv1.SignUpRequest) (*authv1.AuthResponse, error) {
+	signUp := request.SignUpRequest{
+		Name:        req.GetName(),
+		PhoneNumber: req.GetPhoneNumber(),
+		Email:       req.GetEmail(),
+		Password:    req.GetPassword(),
+	}
+	if err := validation.ValidateStruct(signUp); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	user, tokens, err := s.authService.SignUp(ctx, signUp)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &authv1.AuthResponse{User: toUser(user), Tokens: toTokens(tokens)}, nil
+}
+
+func (s *AuthServer) SignIn(ctx context.Context, req *authv1.SignInRequest) (*authv1.AuthResponse, error) {
+	login := request.LoginRequest{
+		PhoneNumber: req.GetPhoneNumber(),
+		Password:    req.GetPassword(),
+	}
+	if err := validation.ValidateStruct(login); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	user, tokens, err := s.authService.SignIn(ctx, login)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &authv1.AuthResponse{User: toUser(user), Tokens: toTokens(tokens)}, nil
+}
+
+func (s *AuthServer) Refresh(ctx context.Context, req *authv1.RefreshRequest) (*authv1.Tokens, error) {
+	refresh := request.RefreshTokenRequest{RefreshToken: req.GetRefreshToken()}
+	if err := validation.ValidateStruct(refresh); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	tokens, err := s.authService.RefreshTokens(ctx, refresh.RefreshToken)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toTokens(tokens), nil
+}
+
+// Logout отзывает access токен из метаданных authorization, как POST /auth/logout
+func (s *AuthServer) Logout(ctx context.Context, _ *authv1.LogoutRequest) (*authv1.LogoutResponse, error) {
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	if err := s.authService.Logout(ctx, token); err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return &authv1.LogoutResponse{}, nil
+}
+
+// ValidateToken проверяет access токен пользователя для другого сервиса.
+// Недействительный токен возвращает Unauthenticated, заблокированный аккаунт — PermissionDenied.
+func (s *AuthServer) ValidateToken(ctx context.Context, req *authv1.ValidateTokenRequest) (*authv1.ValidateTokenResponse, error) {
+	ctx, claims, err := s.auth.authenticate(ctx, req.GetAccessToken())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &authv1.ValidateTokenResponse{
+		UserId: claims.UserID,
+		Roles:  claims.Roles,
+	}
+	if actorID, ok := requestinfo.ImpersonatorFromContext(ctx); ok {
+		resp.ImpersonatorId = actorID
+	}
+	if claims.IssuedAt != nil {
+		resp.IssuedAt = timestamppb.New(claims.IssuedAt.Time)
+	}
+	if claims.ExpiresAt != nil {
+		resp.ExpiresAt = timestamppb.New(claims.ExpiresAt.Time)
+	}
+
+	return resp, nil
+}
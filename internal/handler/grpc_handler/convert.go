@@ -0,0 +1,45 @@
+package grpc_handler
+
+import (
+	"auth_service/internal/model/user"
+	"auth_service/pkg/api/authv1"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func toUser(u *user.User) *authv1.User {
+	resp := u.ToResponse()
+	return &authv1.User{
+		Id:              resp.ID,
+		Name:            resp.Name,
+		DisplayName:     resp.DisplayName,
+		PhoneNumber:     resp.PhoneNumber,
+		Email:           resp.Email,
+		PhotoUrl:        resp.PhotoURL,
+		PhotoThumbnails: resp.PhotoThumbnails,
+		BirthDate:       resp.BirthDate,
+		Locale:          resp.Locale,
+		Timezone:        resp.Timezone,
+		Bio:             resp.Bio,
+		CreatedAt:       timestamppb.New(resp.CreatedAt),
+		UpdatedAt:       timestamppb.New(resp.UpdatedAt),
+	}
+}
+
+func toPublicUser(u *user.User) *authv1.PublicUser {
+	resp := u.ToPublicResponse()
+	return &authv1.PublicUser{
+		Id:              resp.ID,
+		Name:            resp.Name,
+		DisplayName:     resp.DisplayName,
+		PhotoUrl:        resp.PhotoURL,
+		PhotoThumbnails: resp.PhotoThumbnails,
+	}
+}
+
+func toTokens(tokens *user.Tokens) *authv1.Tokens {
+	return &authv1.Tokens{
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+	}
+}
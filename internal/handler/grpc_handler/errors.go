@@ -0,0 +1,122 @@
+package grpc_handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"auth_service/internal/model/user"
+	"auth_service/pkg/apperror"
+	"auth_service/pkg/i18n"
+	"auth_service/pkg/requestinfo"
+	"auth_service/pkg/validation"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// ErrorDomain — домен ErrorInfo: по нему клиент понимает, что Reason — код ошибки этого сервиса
+const ErrorDomain = "auth_service"
+
+var (
+	errValidation = apperror.New(apperror.KindValidation, "validation_failed", "validation failed")
+
+	kindCodes = map[apperror.Kind]codes.Code{
+		apperror.KindInternal:         codes.Internal,
+		apperror.KindInvalid:          codes.InvalidArgument,
+		apperror.KindUnauthorized:     codes.Unauthenticated,
+		apperror.KindForbidden:        codes.PermissionDenied,
+		apperror.KindNotFound:         codes.NotFound,
+		apperror.KindMethodNotAllowed: codes.Unimplemented,
+		apperror.KindConflict:         codes.AlreadyExists,
+		apperror.KindTooLarge:         codes.InvalidArgument,
+		apperror.KindUnsupported:      codes.InvalidArgument,
+		apperror.KindValidation:       codes.InvalidArgument,
+		apperror.KindRateLimited:      codes.ResourceExhausted,
+	}
+)
+
+// toStatus — аналог response.Error для gRPC. Код ошибки попадает в ErrorInfo.Reason,
+// сообщение переводится на язык запроса, ошибки без кода пишутся в лог
+// и отдаются клиенту как internal_error.
+func toStatus(ctx context.Context, err error) error {
+	locale := i18n.FromContext(ctx)
+
+	var statusErr *user.StatusError
+	var validationErrs validation.ValidationErrors
+
+	switch {
+	case errors.As(err, &statusErr):
+		code := "account_" + statusErr.Status
+		message := translate(locale, code, statusErr.Error())
+		metadata := map[string]string{"status": statusErr.Status}
+		if statusErr.Status == user.StatusSuspended && statusErr.Until != nil {
+			until := statusErr.Until.UTC().Format(time.RFC3339)
+			message = i18n.T(locale, "account_suspended_until", until)
+			metadata["until"] = until
+		}
+		return newStatus(codes.PermissionDenied, code, message, metadata)
+
+	case errors.As(err, &validationErrs):
+		message := translate(locale, errValidation.Code, errValidation.Message)
+		st := status.New(codes.InvalidArgument, message)
+
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs.Localize(locale) {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{
+				Field:       fieldErr.Field,
+				Description: fieldErr.Message,
+			})
+		}
+		return withDetails(st,
+			&errdetails.ErrorInfo{Reason: errValidation.Code, Domain: ErrorDomain},
+			&errdetails.BadRequest{FieldViolations: violations},
+		)
+	}
+
+	appErr, ok := apperror.As(err)
+	if !ok || appErr.Kind == apperror.KindInternal {
+		log.Printf("[%s] grpc: %v", requestinfo.FromContext(ctx).RequestID, err)
+		appErr = apperror.ErrInternal
+	}
+
+	var metadata map[string]string
+	if len(appErr.Details) > 0 {
+		metadata = make(map[string]string, len(appErr.Details))
+		for key, value := range appErr.Details {
+			metadata[key] = fmt.Sprint(value)
+		}
+	}
+
+	message := translate(locale, appErr.Code, appErr.Message, appErr.Params...)
+	return newStatus(kindCodes[appErr.Kind], appErr.Code, message, metadata)
+}
+
+func newStatus(code codes.Code, reason, message string, metadata map[string]string) error {
+	return withDetails(status.New(code, message), &errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ErrorDomain,
+		Metadata: metadata,
+	})
+}
+
+// withDetails прикладывает детали к статусу; если это не удалось, статус уходит без них
+func withDetails(st *status.Status, details ...protoadapt.MessageV1) error {
+	detailed, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return detailed.Err()
+}
+
+// translate берет перевод кода ошибки из каталога, иначе исходное сообщение
+func translate(locale, code, fallback string, params ...interface{}) string {
+	if message, ok := i18n.Lookup(locale, code, params...); ok {
+		return message
+	}
+	return fallback
+}
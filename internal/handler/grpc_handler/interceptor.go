@@ -0,0 +1,184 @@
+package grpc_handler
+
+import (
+	"context"
+	"log"
+	"net"
+	"strings"
+	"time"
+
+	"auth_service/internal/middleware"
+	tokenrepo "auth_service/internal/repository/token"
+	userrepo "auth_service/internal/repository/user"
+	"auth_service/pkg/api/authv1"
+	"auth_service/pkg/i18n"
+	"auth_service/pkg/jwt"
+	"auth_service/pkg/requestinfo"
+
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Ключи метаданных. gRPC передает их в нижнем регистре.
+const (
+	authorizationKey  = "authorization"
+	acceptLanguageKey = "accept-language"
+	requestIDKey      = "x-request-id"
+	userAgentKey      = "user-agent"
+	serviceTokenKey   = "x-service-token"
+)
+
+// publicMethods вызываются без access токена, как открытые маршруты /auth в HTTP API.
+// ValidateToken проверяет токен из тела запроса, а не из метаданных.
+var publicMethods = map[string]bool{
+	authv1.AuthService_SignUp_FullMethodName:        true,
+	authv1.AuthService_SignIn_FullMethodName:        true,
+	authv1.AuthService_Refresh_FullMethodName:       true,
+	authv1.AuthService_ValidateToken_FullMethodName: true,
+}
+
+// serviceMethods вызывают другие сервисы, как внутренний HTTP API: вместо access токена
+// нужен x-service-token из internal.servicetokens
+var serviceMethods = map[string]bool{
+	authv1.ProfileService_GetUsersByIDs_FullMethodName: true,
+}
+
+// requestInfoInterceptor — аналог RequestInfoMiddleware и LocaleMiddleware:
+// сохраняет в контексте IP, User-Agent, ID запроса и язык ответа
+func requestInfoInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	requestID := strings.TrimSpace(firstValue(md, requestIDKey))
+	if requestID == "" || len(requestID) > 64 {
+		requestID = uuid.NewString()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDKey, requestID))
+
+	ctx = requestinfo.NewContext(ctx, requestinfo.Info{
+		IP:        peerIP(ctx),
+		UserAgent: firstValue(md, userAgentKey),
+		RequestID: requestID,
+	})
+
+	locale, ok := i18n.FromAcceptLanguage(firstValue(md, acceptLanguageKey))
+	if !ok {
+		locale = i18n.Default()
+	}
+
+	return handler(i18n.WithLocale(ctx, locale), req)
+}
+
+// loggingInterceptor пишет в лог метод, код ответа и длительность вызова
+func loggingInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+
+	resp, err := handler(ctx, req)
+
+	log.Printf("[gRPC] %s %s %s %v %s",
+		info.FullMethod,
+		requestinfo.FromContext(ctx).IP,
+		status.Code(err),
+		time.Since(start),
+		requestinfo.FromContext(ctx).RequestID,
+	)
+	return resp, err
+}
+
+// authenticator проверяет access токены по тем же правилам, что и AuthMiddleware
+type authenticator struct {
+	userRepo      *userrepo.UserRepository
+	tokenRepo     *tokenrepo.TokenRepository
+	serviceTokens []string
+}
+
+// unaryInterceptor требует токен сервиса для serviceMethods и заголовок
+// authorization: Bearer <token> для остальных методов, кроме publicMethods,
+// и кладет пользователя и роли в контекст
+func (a *authenticator) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	if serviceMethods[info.FullMethod] {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if !middleware.ValidServiceToken(firstValue(md, serviceTokenKey), a.serviceTokens) {
+			return nil, toStatus(ctx, middleware.ErrServiceUnauthorized)
+		}
+		return handler(ctx, req)
+	}
+
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	ctx, _, err = a.authenticate(ctx, token)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return handler(ctx, req)
+}
+
+// authenticate проверяет токен и статус аккаунта. Если клиент не передал accept-language,
+// язык ответа берется из профиля, как в AuthMiddleware. Возвращенный контекст
+// нужен и при ошибке: в нем уже выбран язык.
+func (a *authenticator) authenticate(ctx context.Context, token string) (context.Context, *jwt.Claims, error) {
+	claims, account, err := middleware.Authenticate(ctx, a.userRepo, a.tokenRepo, token)
+	if err != nil {
+		return ctx, nil, err
+	}
+
+	md, _ := metadata.FromIncomingContext(ctx)
+	if firstValue(md, acceptLanguageKey) == "" && account.Locale.Valid {
+		if locale, ok := i18n.Normalize(account.Locale.String); ok {
+			ctx = i18n.WithLocale(ctx, locale)
+		}
+	}
+
+	if err := account.CheckStatus(time.Now()); err != nil {
+		return ctx, nil, err
+	}
+
+	return middleware.WithIdentity(ctx, claims), claims, nil
+}
+
+// bearerToken достает access токен из метаданных authorization
+func bearerToken(ctx context.Context) (string, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	authHeader := firstValue(md, authorizationKey)
+	if authHeader == "" {
+		return "", middleware.ErrAuthorizationRequired
+	}
+
+	parts := strings.Split(authHeader, " ")
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return "", middleware.ErrAuthorizationFormat
+	}
+
+	return parts[1], nil
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func peerIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
@@ -0,0 +1,48 @@
+package grpc_handler
+
+import (
+	"context"
+
+	"auth_service/internal/middleware"
+	profileService "auth_service/internal/service/profile"
+	"auth_service/pkg/api/authv1"
+	"auth_service/pkg/apperror"
+)
+
+type ProfileServer struct {
+	authv1.UnimplementedProfileServiceServer
+
+	profileService *profileService.ProfileService
+}
+
+func NewProfileServer(profileService *profileService.ProfileService) *ProfileServer {
+	return &ProfileServer{profileService: profileService}
+}
+
+func (s *ProfileServer) GetProfile(ctx context.Context, _ *authv1.GetProfileRequest) (*authv1.User, error) {
+	userID, ok := middleware.GetUserIDFromContext(ctx)
+	if !ok {
+		return nil, toStatus(ctx, apperror.ErrUnauthorized)
+	}
+
+	user, err := s.profileService.GetProfile(ctx, userID)
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	return toUser(user), nil
+}
+
+func (s *ProfileServer) GetUsersByIDs(ctx context.Context, req *authv1.GetUsersByIDsRequest) (*authv1.GetUsersByIDsResponse, error) {
+	users, err := s.profileService.GetUsersByIDs(ctx, req.GetIds())
+	if err != nil {
+		return nil, toStatus(ctx, err)
+	}
+
+	resp := &authv1.GetUsersByIDsResponse{Users: make([]*authv1.PublicUser, 0, len(users))}
+	for i := range users {
+		resp.Users = append(resp.Users, toPublicUser(&users[i]))
+	}
+
+	return resp, nil
+}
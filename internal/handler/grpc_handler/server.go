@@ -0,0 +1,40 @@
+package grpc_handler
+
+import (
+	tokenrepo "auth_service/internal/repository/token"
+	userrepo "auth_service/internal/repository/user"
+	authService "auth_service/internal/service/auth"
+	profileService "auth_service/internal/service/profile"
+	"auth_service/pkg/api/authv1"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// NewServer собирает gRPC сервер. Цепочка интерцепторов повторяет middleware HTTP API:
+// сведения о запросе и язык, лог, затем проверка access токена или токена сервиса.
+// Вместе с сервером возвращается health сервер, чтобы при остановке перевести его в NOT_SERVING.
+func NewServer(
+	authService *authService.AuthService,
+	profileService *profileService.ProfileService,
+	userRepo *userrepo.UserRepository,
+	tokenRepo *tokenrepo.TokenRepository,
+	serviceTokens []string,
+) (*grpc.Server, *health.Server) {
+	auth := &authenticator{userRepo: userRepo, tokenRepo: tokenRepo, serviceTokens: serviceTokens}
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		requestInfoInterceptor,
+		loggingInterceptor,
+		auth.unaryInterceptor,
+	))
+
+	authv1.RegisterAuthServiceServer(server, NewAuthServer(authService, userRepo, tokenRepo))
+	authv1.RegisterProfileServiceServer(server, NewProfileServer(profileService))
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	return server, healthServer
+}
@@ -0,0 +1,59 @@
+package internal_handler
+
+import (
+	"net/http"
+
+	"auth_service/internal/handler/response"
+	"auth_service/internal/model/request"
+	"auth_service/internal/model/responce"
+	profileService "auth_service/internal/service/profile"
+)
+
+type Internal_Handler interface {
+	GetUsersBatch(w http.ResponseWriter, r *http.Request)
+}
+
+// InternalHandler обслуживает внутренний API для других сервисов
+type InternalHandler struct {
+	profileService *profileService.ProfileService
+}
+
+func NewInternalHandler(profileService *profileService.ProfileService) *InternalHandler {
+	return &InternalHandler{profileService: profileService}
+}
+
+// GetUsersBatch
+// @Summary Публичные профили пользователей по ID
+// @Description Возвращает карточки пользователей без контактных данных в порядке запроса. Повторы схлопываются, удаленные и несуществующие ID пропускаются. Не больше 100 различных ID за запрос
+// @Tags Internal
+// @Security ServiceAuth
+// @Accept json
+// @Produce json
+// @Param request body request.BatchUsersRequest true "ID пользователей"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 401 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /internal/v1/users/batch [post]
+func (h *InternalHandler) GetUsersBatch(w http.ResponseWriter, r *http.Request) {
+	var req request.BatchUsersRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	users, err := h.profileService.GetUsersByIDs(r.Context(), req.IDs)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	list := make([]responce.PublicUserResponse, 0, len(users))
+	for i := range users {
+		list = append(list, users[i].ToPublicResponse())
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    list,
+	}, http.StatusOK)
+}
@@ -2,27 +2,65 @@ package profile_handler
 
 import (
 	"encoding/json"
+	"errors"
+	"mime"
 	"net/http"
 
-	"auth_service/internal/handler/auth"
+	"auth_service/internal/config"
+	"auth_service/internal/handler/response"
 	"auth_service/internal/middleware"
+	"auth_service/internal/model/audit"
 	"auth_service/internal/model/request"
+	"auth_service/internal/model/responce"
+	authService "auth_service/internal/service/auth"
+	exportService "auth_service/internal/service/export"
 	profileService "auth_service/internal/service/profile"
+	"auth_service/pkg/apperror"
+	"auth_service/pkg/imageproc"
+
+	"github.com/gorilla/mux"
+)
+
+// multipartOverhead — запас на заголовки multipart сверх максимального размера фото
+const multipartOverhead = 1 << 20
+
+var (
+	errPhotoRequired        = apperror.New(apperror.KindInvalid, "photo_required", "no photo uploaded")
+	errUnsupportedMediaType = apperror.New(apperror.KindUnsupported, "unsupported_media_type", "content type must be application/merge-patch+json")
 )
 
 type Profile_Handler interface {
 	GetProfile(w http.ResponseWriter, r *http.Request)
 	UpdateProfile(w http.ResponseWriter, r *http.Request)
+	PatchProfile(w http.ResponseWriter, r *http.Request)
 	DeleteProfile(w http.ResponseWriter, r *http.Request)
 	UploadPhoto(w http.ResponseWriter, r *http.Request)
+	CreatePhotoUploadURL(w http.ResponseWriter, r *http.Request)
+	ConfirmPhotoUpload(w http.ResponseWriter, r *http.Request)
+	DeletePhoto(w http.ResponseWriter, r *http.Request)
+	GetActivity(w http.ResponseWriter, r *http.Request)
+	RequestPhoneChange(w http.ResponseWriter, r *http.Request)
+	ConfirmPhoneChange(w http.ResponseWriter, r *http.Request)
+	RequestExport(w http.ResponseWriter, r *http.Request)
+	GetExport(w http.ResponseWriter, r *http.Request)
 }
 
 type ProfileHandler struct {
 	profileService *profileService.ProfileService
+	authService    *authService.AuthService
+	exportService  *exportService.ExportService
 }
 
-func NewProfileHandler(profileService *profileService.ProfileService) *ProfileHandler {
-	return &ProfileHandler{profileService: profileService}
+func NewProfileHandler(
+	profileService *profileService.ProfileService,
+	authService *authService.AuthService,
+	exportService *exportService.ExportService,
+) *ProfileHandler {
+	return &ProfileHandler{
+		profileService: profileService,
+		authService:    authService,
+		exportService:  exportService,
+	}
 }
 
 // GetProfile
@@ -32,23 +70,23 @@ func NewProfileHandler(profileService *profileService.ProfileService) *ProfileHa
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
 // @Router /api/v1/profile [get]
 func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		auth.ErrorResponse(w, "unauthorized", http.StatusUnauthorized)
+		response.Error(w, r, apperror.ErrUnauthorized)
 		return
 	}
 
 	ctx := r.Context()
 	user, err := h.profileService.GetProfile(ctx, userID)
 	if err != nil {
-		auth.ErrorResponse(w, "profile not found", http.StatusNotFound)
+		response.Error(w, r, err)
 		return
 	}
 
-	auth.JsonResponse(w, map[string]interface{}{
+	response.JSON(w, map[string]interface{}{
 		"success": true,
 		"data":    user.ToResponse(),
 	}, http.StatusOK)
@@ -63,37 +101,83 @@ func (h *ProfileHandler) GetProfile(w http.ResponseWriter, r *http.Request) {
 // @Produce json
 // @Param request body db.UpdateProfileRequest true "Новые данные"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 401 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
 // @Router /api/v1/profile [put]
 func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		auth.ErrorResponse(w, "unauthorized", http.StatusUnauthorized)
+		response.Error(w, r, apperror.ErrUnauthorized)
 		return
 	}
 
-	var req struct {
-		Name  string `json:"name"`
-		Email string `json:"email"`
+	var req request.UpdateProfileRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
 	}
 
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		auth.ErrorResponse(w, "invalid request body", http.StatusBadRequest)
+	ctx := r.Context()
+	user, err := h.profileService.UpdateProfile(ctx, userID, req)
+	if err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
-	ctx := r.Context()
-	user, err := h.profileService.UpdateProfile(ctx, userID, request.UpdateProfileRequest{
-		Name:  req.Name,
-		Email: req.Email,
-	})
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    user.ToResponse(),
+		"message": "profile updated successfully",
+	}, http.StatusOK)
+}
+
+// PatchProfile
+// @Summary Частичное обновление профиля
+// @Description Обновляет профиль по JSON Merge Patch (RFC 7396): отсутствующие поля не меняются, null очищает поле
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.PatchProfileRequest true "Изменяемые поля"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 401 {object} responce.Problem
+// @Failure 415 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/profile [patch]
+func (h *ProfileHandler) PatchProfile(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	contentType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if contentType != "application/merge-patch+json" && contentType != "application/json" {
+		response.Error(w, r, errUnsupportedMediaType)
+		return
+	}
+
+	var req request.PatchProfileRequest
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		response.Error(w, r, apperror.ErrInvalidBody)
+		return
+	}
+
+	if !response.ValidateRequest(w, r, &req) {
+		return
+	}
+
+	user, err := h.profileService.PatchProfile(r.Context(), userID, req)
 	if err != nil {
-		auth.ErrorResponse(w, err.Error(), http.StatusBadRequest)
+		response.Error(w, r, err)
 		return
 	}
 
-	auth.JsonResponse(w, map[string]interface{}{
+	response.JSON(w, map[string]interface{}{
 		"success": true,
 		"data":    user.ToResponse(),
 		"message": "profile updated successfully",
@@ -102,28 +186,28 @@ func (h *ProfileHandler) UpdateProfile(w http.ResponseWriter, r *http.Request) {
 
 // DeleteProfile
 // @Summary Удаление профиля
-// @Description Мягкое удаление профиля
+// @Description Мягкое удаление профиля. В течение льготного периода (deletion.graceperiod) аккаунт можно восстановить через /auth/restore, после него персональные данные и файлы удаляются безвозвратно
 // @Tags Profile
 // @Security BearerAuth
 // @Produce json
 // @Success 200 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
 // @Router /api/v1/profile [delete]
 func (h *ProfileHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		auth.ErrorResponse(w, "unauthorized", http.StatusUnauthorized)
+		response.Error(w, r, apperror.ErrUnauthorized)
 		return
 	}
 
 	ctx := r.Context()
 	err := h.profileService.DeleteProfile(ctx, userID)
 	if err != nil {
-		auth.ErrorResponse(w, "failed to delete profile", http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
-	auth.JsonResponse(w, map[string]interface{}{
+	response.JSON(w, map[string]interface{}{
 		"success": true,
 		"message": "profile deleted successfully",
 	}, http.StatusOK)
@@ -131,54 +215,356 @@ func (h *ProfileHandler) DeleteProfile(w http.ResponseWriter, r *http.Request) {
 
 // UploadPhoto
 // @Summary Загрузка фото профиля
-// @Description Загружает изображение для профиля
+// @Description Загружает изображение для профиля. Файл декодируется на сервере (JPEG или PNG), метаданные EXIF удаляются, создаются миниатюры
 // @Tags Profile
 // @Security BearerAuth
 // @Accept multipart/form-data
 // @Produce json
 // @Param photo formData file true "Файл изображения"
 // @Success 200 {object} map[string]interface{}
-// @Failure 400 {object} map[string]interface{}
-// @Failure 401 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 401 {object} responce.Problem
+// @Failure 413 {object} responce.Problem
 // @Router /api/v1/profile/photo [post]
 func (h *ProfileHandler) UploadPhoto(w http.ResponseWriter, r *http.Request) {
 	userID, ok := middleware.GetUserIDFromContext(r.Context())
 	if !ok {
-		auth.ErrorResponse(w, "unauthorized", http.StatusUnauthorized)
+		response.Error(w, r, apperror.ErrUnauthorized)
 		return
 	}
 
+	r.Body = http.MaxBytesReader(w, r.Body, config.App.Photo.MaxBytes+multipartOverhead)
+
 	err := r.ParseMultipartForm(10 << 20)
 	if err != nil {
-		auth.ErrorResponse(w, "file too large", http.StatusBadRequest)
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			response.Error(w, r, imageproc.ErrTooLarge)
+			return
+		}
+		response.Error(w, r, apperror.ErrInvalidBody)
 		return
 	}
 
-	file, header, err := r.FormFile("photo")
+	file, _, err := r.FormFile("photo")
 	if err != nil {
-		auth.ErrorResponse(w, "no photo uploaded", http.StatusBadRequest)
+		response.Error(w, r, errPhotoRequired)
 		return
 	}
 	defer file.Close()
 
-	contentType := header.Header.Get("Content-Type")
-	if contentType != "image/jpeg" && contentType != "image/png" && contentType != "image/jpg" {
-		auth.ErrorResponse(w, "only JPEG and PNG images are allowed", http.StatusBadRequest)
+	ctx := r.Context()
+	photo, err := h.profileService.UploadPhoto(ctx, userID, file)
+	if err != nil {
+		response.Error(w, r, err)
 		return
 	}
 
-	ctx := r.Context()
-	photoURL, err := h.profileService.UploadPhoto(ctx, userID, file, header.Filename, header.Size)
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    photo,
+		"message": "photo uploaded successfully",
+	}, http.StatusOK)
+}
+
+// CreatePhotoUploadURL
+// @Summary Получение URL для прямой загрузки фото
+// @Description Возвращает presigned PUT URL для загрузки фото напрямую в хранилище. Клиент обязан передать заголовки из ответа без изменений, после загрузки вызвать /api/v1/profile/photo/confirm
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.PhotoUploadURLRequest true "Тип и размер файла"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 401 {object} responce.Problem
+// @Failure 413 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/profile/photo/upload-url [post]
+func (h *ProfileHandler) CreatePhotoUploadURL(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	var req request.PhotoUploadURLRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	upload, err := h.profileService.CreatePhotoUploadURL(r.Context(), userID, req)
 	if err != nil {
-		auth.ErrorResponse(w, err.Error(), http.StatusInternalServerError)
+		response.Error(w, r, err)
 		return
 	}
 
-	auth.JsonResponse(w, map[string]interface{}{
+	response.JSON(w, map[string]interface{}{
 		"success": true,
-		"data": map[string]string{
-			"photo_url": photoURL,
-		},
+		"data":    upload,
+	}, http.StatusOK)
+}
+
+// ConfirmPhotoUpload
+// @Summary Подтверждение прямой загрузки фото
+// @Description Проверяет загруженный в хранилище файл так же, как при обычной загрузке, и устанавливает его фото профиля
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.ConfirmPhotoUploadRequest true "Ключ загруженного объекта"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 401 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Failure 413 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/profile/photo/confirm [post]
+func (h *ProfileHandler) ConfirmPhotoUpload(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	var req request.ConfirmPhotoUploadRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	photo, err := h.profileService.ConfirmPhotoUpload(r.Context(), userID, req.ObjectKey)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    photo,
 		"message": "photo uploaded successfully",
 	}, http.StatusOK)
 }
+
+// DeletePhoto
+// @Summary Удаление фото профиля
+// @Description Убирает фото из профиля и удаляет файлы из хранилища
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/profile/photo [delete]
+func (h *ProfileHandler) DeletePhoto(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	err := h.profileService.DeletePhoto(r.Context(), userID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"message": "photo deleted successfully",
+	}, http.StatusOK)
+}
+
+// RequestPhoneChange
+// @Summary Запрос смены номера телефона
+// @Description Проверяет текущий пароль и отправляет код подтверждения на новый номер
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.ChangePhoneRequest true "Новый номер и текущий пароль"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 401 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Failure 409 {object} responce.Problem
+// @Failure 429 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/profile/phone [post]
+func (h *ProfileHandler) RequestPhoneChange(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	var req request.ChangePhoneRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	expiresAt, err := h.authService.RequestPhoneChange(r.Context(), userID, req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"expires_at": expiresAt,
+		},
+		"message": "verification code sent",
+	}, http.StatusOK)
+}
+
+// ConfirmPhoneChange
+// @Summary Подтверждение смены номера телефона
+// @Description Проверяет код из SMS и меняет номер. Остальные сессии завершаются, текущая получает новые токены
+// @Tags Profile
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.ConfirmPhoneChangeRequest true "Код подтверждения"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 401 {object} responce.Problem
+// @Failure 409 {object} responce.Problem
+// @Failure 429 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/profile/phone/confirm [post]
+func (h *ProfileHandler) ConfirmPhoneChange(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	var req request.ConfirmPhoneChangeRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	user, tokens, err := h.authService.ConfirmPhoneChange(r.Context(), userID, req.Code)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data": map[string]interface{}{
+			"user":   user.ToResponse(),
+			"tokens": tokens,
+		},
+		"message": "phone number changed successfully",
+	}, http.StatusOK)
+}
+
+// GetActivity
+// @Summary История активности
+// @Description Возвращает события безопасности по аккаунту: входы, выходы, изменения профиля
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Номер страницы" default(1)
+// @Param page_size query int false "Размер страницы" default(20)
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 401 {object} responce.Problem
+// @Router /api/v1/profile/activity [get]
+func (h *ProfileHandler) GetActivity(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	page, pageSize, err := response.ParsePagination(r)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	events, total, err := h.profileService.GetActivity(r.Context(), userID, pageSize, (page-1)*pageSize)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	list := responce.ActivityListResponse{
+		Events:   make([]audit.Activity, 0, len(events)),
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+	}
+	for i := range events {
+		list.Events = append(list.Events, events[i].ToActivity())
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    list,
+	}, http.StatusOK)
+}
+
+// RequestExport
+// @Summary Выгрузка данных аккаунта
+// @Description Ставит в очередь сборку ZIP архива с профилем, историей сессий, журналом событий и фото. Если выгрузка уже выполняется, возвращает ее.
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Success 202 {object} export.JobResponse
+// @Failure 401 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Failure 429 {object} responce.Problem
+// @Router /api/v1/profile/export [post]
+func (h *ProfileHandler) RequestExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	job, err := h.exportService.RequestExport(r.Context(), userID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	w.Header().Set("Location", "/api/v1/profile/export/"+job.ID)
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    job,
+		"message": "export scheduled",
+	}, http.StatusAccepted)
+}
+
+// GetExport
+// @Summary Статус выгрузки данных аккаунта
+// @Description Возвращает статус выгрузки, для готовой выгрузки — временную ссылку на архив
+// @Tags Profile
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "ID выгрузки"
+// @Success 200 {object} export.JobResponse
+// @Failure 401 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/profile/export/{id} [get]
+func (h *ProfileHandler) GetExport(w http.ResponseWriter, r *http.Request) {
+	userID, ok := middleware.GetUserIDFromContext(r.Context())
+	if !ok {
+		response.Error(w, r, apperror.ErrUnauthorized)
+		return
+	}
+
+	job, err := h.exportService.GetExport(r.Context(), userID, mux.Vars(r)["id"])
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    job,
+	}, http.StatusOK)
+}
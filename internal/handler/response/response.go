@@ -0,0 +1,230 @@
+package response
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth_service/internal/config"
+	"auth_service/internal/model/responce"
+	"auth_service/internal/model/user"
+	"auth_service/pkg/apperror"
+	"auth_service/pkg/i18n"
+	"auth_service/pkg/requestinfo"
+	"auth_service/pkg/validation"
+)
+
+const (
+	ContentTypeJSON    = "application/json"
+	ContentTypeProblem = "application/problem+json"
+)
+
+var (
+	errValidation = apperror.New(apperror.KindValidation, "validation_failed", "validation failed")
+
+	kindStatus = map[apperror.Kind]int{
+		apperror.KindInternal:         http.StatusInternalServerError,
+		apperror.KindInvalid:          http.StatusBadRequest,
+		apperror.KindUnauthorized:     http.StatusUnauthorized,
+		apperror.KindForbidden:        http.StatusForbidden,
+		apperror.KindNotFound:         http.StatusNotFound,
+		apperror.KindMethodNotAllowed: http.StatusMethodNotAllowed,
+		apperror.KindConflict:         http.StatusConflict,
+		apperror.KindTooLarge:         http.StatusRequestEntityTooLarge,
+		apperror.KindUnsupported:      http.StatusUnsupportedMediaType,
+		apperror.KindValidation:       http.StatusUnprocessableEntity,
+		apperror.KindRateLimited:      http.StatusTooManyRequests,
+	}
+)
+
+func JSON(w http.ResponseWriter, data interface{}, statusCode int) {
+	write(w, ContentTypeJSON, data, statusCode)
+}
+
+// Error — единственное место, где ошибки превращаются в HTTP ответ.
+// Тело ответа — problem+json по RFC 7807 с расширениями code и request_id,
+// detail переводится на язык запроса.
+// Ошибки без кода (БД, хранилище, Redis) пишутся в лог и отдаются клиенту как internal_error.
+func Error(w http.ResponseWriter, r *http.Request, err error) {
+	locale := i18n.FromContext(r.Context())
+	problem := toProblem(r, err, locale)
+
+	w.Header().Set("Content-Language", locale)
+	write(w, problemContentType(r), problem, problem.Status)
+}
+
+// toProblem подбирает статус и код ошибки
+func toProblem(r *http.Request, err error, locale string) *responce.Problem {
+	requestID := requestinfo.FromContext(r.Context()).RequestID
+
+	var statusErr *user.StatusError
+	var validationErrs validation.ValidationErrors
+
+	switch {
+	case errors.As(err, &statusErr):
+		code := "account_" + statusErr.Status
+		detail := translate(locale, code, statusErr.Error())
+		if statusErr.Status == user.StatusSuspended && statusErr.Until != nil {
+			detail = i18n.T(locale, "account_suspended_until", statusErr.Until.UTC().Format(time.RFC3339))
+		}
+
+		problem := newProblem(r, http.StatusForbidden, code, detail)
+		problem.Account = accountDetails(statusErr)
+		return problem
+
+	case errors.As(err, &validationErrs):
+		detail := translate(locale, errValidation.Code, errValidation.Message)
+		problem := newProblem(r, http.StatusUnprocessableEntity, errValidation.Code, detail)
+		problem.Errors = validationErrs.Localize(locale)
+		return problem
+	}
+
+	appErr, ok := apperror.As(err)
+	if !ok || appErr.Kind == apperror.KindInternal {
+		log.Printf("[%s] %s %s: %v", requestID, r.Method, r.URL.Path, err)
+		appErr = apperror.ErrInternal
+	}
+
+	detail := translate(locale, appErr.Code, appErr.Message, appErr.Params...)
+	problem := newProblem(r, kindStatus[appErr.Kind], appErr.Code, detail)
+	if len(appErr.Details) > 0 {
+		problem.Details = appErr.Details
+	}
+	return problem
+}
+
+// translate берет перевод кода ошибки из каталога, иначе исходное сообщение
+func translate(locale, code, fallback string, params ...interface{}) string {
+	if message, ok := i18n.Lookup(locale, code, params...); ok {
+		return message
+	}
+	return fallback
+}
+
+// NotFound и MethodNotAllowed подключаются к mux вместо текстовых ответов по умолчанию
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, apperror.ErrNotFound)
+}
+
+func MethodNotAllowed(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, apperror.ErrMethodNotAllowed)
+}
+
+func newProblem(r *http.Request, status int, code, detail string) *responce.Problem {
+	return &responce.Problem{
+		Type:      config.App.Server.ProblemTypeBase + code,
+		Title:     http.StatusText(status),
+		Status:    status,
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Code:      code,
+		RequestID: requestinfo.FromContext(r.Context()).RequestID,
+	}
+}
+
+// problemContentType — клиенты, которые явно принимают только application/json,
+// получают то же тело с этим типом; остальные получают application/problem+json
+func problemContentType(r *http.Request) string {
+	accept := r.Header.Get("Accept")
+	if accept == "" {
+		return ContentTypeProblem
+	}
+
+	acceptsJSON := false
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil || params["q"] == "0" {
+			continue
+		}
+		switch mediaType {
+		case ContentTypeProblem, "application/*", "*/*":
+			return ContentTypeProblem
+		case ContentTypeJSON:
+			acceptsJSON = true
+		}
+	}
+
+	if acceptsJSON {
+		return ContentTypeJSON
+	}
+	return ContentTypeProblem
+}
+
+func write(w http.ResponseWriter, contentType string, data interface{}, statusCode int) {
+	w.Header().Set("Content-Type", contentType)
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(data)
+}
+
+func accountDetails(statusErr *user.StatusError) map[string]interface{} {
+	details := map[string]interface{}{
+		"status": statusErr.Status,
+	}
+	if statusErr.Reason != "" {
+		details["reason"] = statusErr.Reason
+	}
+	if statusErr.Until != nil {
+		details["until"] = statusErr.Until
+	}
+	return details
+}
+
+// DecodeJSON читает тело запроса в dst и проверяет теги validate.
+// При ошибке отвечает 400 (невалидный JSON) или 422 (нарушены правила) и возвращает false.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, dst interface{}) bool {
+	if err := json.NewDecoder(r.Body).Decode(dst); err != nil {
+		Error(w, r, apperror.ErrInvalidBody)
+		return false
+	}
+
+	return ValidateRequest(w, r, dst)
+}
+
+// ValidateRequest проверяет теги validate уже разобранного запроса
+func ValidateRequest(w http.ResponseWriter, r *http.Request, req interface{}) bool {
+	if err := validation.ValidateStruct(req); err != nil {
+		Error(w, r, err)
+		return false
+	}
+	return true
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// ParsePagination читает параметры page и page_size из строки запроса
+func ParsePagination(r *http.Request) (page, pageSize int, err error) {
+	query := r.URL.Query()
+
+	page, err = parsePositiveInt(query.Get("page"), 1)
+	if err != nil {
+		return 0, 0, apperror.InvalidParameter("page")
+	}
+
+	pageSize, err = parsePositiveInt(query.Get("page_size"), defaultPageSize)
+	if err != nil || pageSize > maxPageSize {
+		return 0, 0, apperror.InvalidParameter("page_size")
+	}
+
+	return page, pageSize, nil
+}
+
+func parsePositiveInt(value string, fallback int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, strconv.ErrSyntax
+	}
+
+	return n, nil
+}
@@ -1,9 +1,16 @@
 package router
 
 import (
+	"auth_service/internal/config"
+	"auth_service/internal/handler/admin_handler"
 	"auth_service/internal/handler/auth"
+	"auth_service/internal/handler/internal_handler"
 	"auth_service/internal/handler/profile_handler"
+	"auth_service/internal/handler/response"
+	"auth_service/internal/handler/webhook_handler"
 	"auth_service/internal/middleware"
+	"auth_service/internal/model/role"
+	rolerepo "auth_service/internal/repository/role"
 	tokenrepo "auth_service/internal/repository/token"
 	userrepo "auth_service/internal/repository/user"
 	"encoding/json"
@@ -16,12 +23,25 @@ import (
 func SetupRouter(
 	authHandler *auth.AuthHandler,
 	profileHandler *profile_handler.ProfileHandler,
+	adminHandler *admin_handler.AdminHandler,
+	webhookHandler *webhook_handler.WebhookHandler,
+	internalHandler *internal_handler.InternalHandler,
 	userRepo *userrepo.UserRepository,
 	tokenRepo *tokenrepo.TokenRepository,
+	roleRepo *rolerepo.RoleRepository,
 ) *mux.Router {
 	router := mux.NewRouter()
 
+	// mux не применяет middleware к ненайденным маршрутам, поэтому оборачиваем сами
+	fallback := func(handler http.HandlerFunc) http.Handler {
+		return middleware.CORSMiddleware(middleware.RequestInfoMiddleware(middleware.LocaleMiddleware(middleware.LoggingMiddleware(handler))))
+	}
+	router.NotFoundHandler = fallback(response.NotFound)
+	router.MethodNotAllowedHandler = fallback(response.MethodNotAllowed)
+
 	router.Use(middleware.CORSMiddleware)
+	router.Use(middleware.RequestInfoMiddleware)
+	router.Use(middleware.LocaleMiddleware)
 	router.Use(middleware.LoggingMiddleware)
 	router.Use(middleware.AuthMiddleware(userRepo, tokenRepo))
 
@@ -45,13 +65,60 @@ func SetupRouter(
 	auth.HandleFunc("/signup", authHandler.SignUp).Methods("POST")
 	auth.HandleFunc("/signin", authHandler.SignIn).Methods("POST")
 	auth.HandleFunc("/refresh", authHandler.Refresh).Methods("POST")
+	auth.HandleFunc("/restore", authHandler.RequestRestore).Methods("POST")
+	auth.HandleFunc("/restore/confirm", authHandler.ConfirmRestore).Methods("POST")
 
 	profile := api.PathPrefix("/profile").Subrouter()
 	profile.HandleFunc("", profileHandler.GetProfile).Methods("GET")
 	profile.HandleFunc("", profileHandler.UpdateProfile).Methods("PUT")
-	profile.HandleFunc("", profileHandler.DeleteProfile).Methods("DELETE")
+	profile.HandleFunc("", profileHandler.PatchProfile).Methods("PATCH")
+	profile.Handle("", middleware.DenyImpersonation(http.HandlerFunc(profileHandler.DeleteProfile))).Methods("DELETE")
 	profile.HandleFunc("/logout", authHandler.Logout).Methods("POST")
+	profile.Handle("/logout-all", middleware.DenyImpersonation(http.HandlerFunc(authHandler.LogoutAll))).Methods("POST")
 	profile.HandleFunc("/photo", profileHandler.UploadPhoto).Methods("POST")
+	profile.HandleFunc("/photo", profileHandler.DeletePhoto).Methods("DELETE")
+	profile.HandleFunc("/photo/upload-url", profileHandler.CreatePhotoUploadURL).Methods("POST")
+	profile.HandleFunc("/photo/confirm", profileHandler.ConfirmPhotoUpload).Methods("POST")
+	profile.HandleFunc("/activity", profileHandler.GetActivity).Methods("GET")
+	profile.Handle("/phone", middleware.DenyImpersonation(http.HandlerFunc(profileHandler.RequestPhoneChange))).Methods("POST")
+	profile.Handle("/phone/confirm", middleware.DenyImpersonation(http.HandlerFunc(profileHandler.ConfirmPhoneChange))).Methods("POST")
+	profile.Handle("/export", middleware.DenyImpersonation(http.HandlerFunc(profileHandler.RequestExport))).Methods("POST")
+	profile.Handle("/export/{id}", middleware.DenyImpersonation(http.HandlerFunc(profileHandler.GetExport))).Methods("GET")
+
+	guard := func(permission string, handler http.HandlerFunc) http.Handler {
+		return middleware.RequirePermission(roleRepo, permission)(handler)
+	}
+
+	admin := api.PathPrefix("/admin").Subrouter()
+	admin.Handle("/roles", guard(role.PermRolesManage, adminHandler.ListRoles)).Methods("GET")
+	admin.Handle("/users/{id:[0-9]+}/roles", guard(role.PermUsersRead, adminHandler.GetUserRoles)).Methods("GET")
+	admin.Handle("/users/{id:[0-9]+}/roles", guard(role.PermRolesManage, adminHandler.AssignRole)).Methods("POST")
+	admin.Handle("/users/{id:[0-9]+}/roles/{role}", guard(role.PermRolesManage, adminHandler.RevokeRole)).Methods("DELETE")
+	admin.Handle("/users", guard(role.PermUsersRead, adminHandler.ListUsers)).Methods("GET")
+	admin.Handle("/users/{id:[0-9]+}", guard(role.PermUsersRead, adminHandler.GetUser)).Methods("GET")
+	admin.Handle("/users/{id:[0-9]+}", guard(role.PermUsersWrite, adminHandler.UpdateUser)).Methods("PUT")
+	admin.Handle("/users/{id:[0-9]+}/password-reset", guard(role.PermUsersWrite, adminHandler.ResetPassword)).Methods("POST")
+	admin.Handle("/users/{id:[0-9]+}/block", guard(role.PermUsersBlock, adminHandler.BlockUser)).Methods("POST")
+	admin.Handle("/users/{id:[0-9]+}/unblock", guard(role.PermUsersBlock, adminHandler.UnblockUser)).Methods("POST")
+	admin.Handle("/users/{id:[0-9]+}/restore", guard(role.PermUsersWrite, adminHandler.RestoreUser)).Methods("POST")
+	admin.Handle("/users/{id:[0-9]+}/sessions", guard(role.PermSessionsRevoke, adminHandler.RevokeSessions)).Methods("DELETE")
+	admin.Handle("/audit", guard(role.PermAuditRead, adminHandler.QueryAudit)).Methods("GET")
+	admin.Handle("/users/{id:[0-9]+}/impersonate", guard(role.PermImpersonate, adminHandler.Impersonate)).Methods("POST")
+
+	admin.Handle("/webhooks", guard(role.PermWebhooksManage, webhookHandler.ListWebhooks)).Methods("GET")
+	admin.Handle("/webhooks", guard(role.PermWebhooksManage, webhookHandler.CreateWebhook)).Methods("POST")
+	admin.Handle("/webhooks/{id:[0-9]+}", guard(role.PermWebhooksManage, webhookHandler.GetWebhook)).Methods("GET")
+	admin.Handle("/webhooks/{id:[0-9]+}", guard(role.PermWebhooksManage, webhookHandler.UpdateWebhook)).Methods("PUT")
+	admin.Handle("/webhooks/{id:[0-9]+}", guard(role.PermWebhooksManage, webhookHandler.DeleteWebhook)).Methods("DELETE")
+	admin.Handle("/webhooks/{id:[0-9]+}/rotate-secret", guard(role.PermWebhooksManage, webhookHandler.RotateSecret)).Methods("POST")
+	admin.Handle("/webhooks/{id:[0-9]+}/deliveries", guard(role.PermWebhooksManage, webhookHandler.ListDeliveries)).Methods("GET")
+	admin.Handle("/webhooks/{id:[0-9]+}/deliveries/{delivery_id:[0-9]+}", guard(role.PermWebhooksManage, webhookHandler.GetDelivery)).Methods("GET")
+	admin.Handle("/webhooks/{id:[0-9]+}/deliveries/{delivery_id:[0-9]+}/retry", guard(role.PermWebhooksManage, webhookHandler.RetryDelivery)).Methods("POST")
+
+	// Внутренний API для других сервисов: AuthMiddleware его пропускает, доступ по токену сервиса
+	internal := router.PathPrefix("/internal/v1").Subrouter()
+	internal.Use(middleware.ServiceAuthMiddleware(config.App.Internal.ServiceTokens))
+	internal.HandleFunc("/users/batch", internalHandler.GetUsersBatch).Methods("POST")
 
 	return router
 }
@@ -0,0 +1,376 @@
+package webhook_handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"auth_service/internal/handler/response"
+	"auth_service/internal/middleware"
+	"auth_service/internal/model/request"
+	"auth_service/internal/model/webhook"
+	webhookService "auth_service/internal/service/webhook"
+	"auth_service/pkg/apperror"
+
+	"github.com/gorilla/mux"
+)
+
+type Webhook_Handler interface {
+	ListWebhooks(w http.ResponseWriter, r *http.Request)
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	GetWebhook(w http.ResponseWriter, r *http.Request)
+	UpdateWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+	RotateSecret(w http.ResponseWriter, r *http.Request)
+	ListDeliveries(w http.ResponseWriter, r *http.Request)
+	GetDelivery(w http.ResponseWriter, r *http.Request)
+	RetryDelivery(w http.ResponseWriter, r *http.Request)
+}
+
+type WebhookHandler struct {
+	webhookService *webhookService.WebhookService
+}
+
+func NewWebhookHandler(webhookService *webhookService.WebhookService) *WebhookHandler {
+	return &WebhookHandler{webhookService: webhookService}
+}
+
+// ListWebhooks
+// @Summary Список webhook
+// @Description Возвращает все зарегистрированные webhook без секретов
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} map[string]interface{}
+// @Failure 401 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Router /api/v1/admin/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.webhookService.ListEndpoints(r.Context())
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	list := make([]webhook.EndpointResponse, 0, len(endpoints))
+	for i := range endpoints {
+		list = append(list, endpoints[i].ToResponse())
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    list,
+	}, http.StatusOK)
+}
+
+// CreateWebhook
+// @Summary Регистрация webhook
+// @Description Регистрирует адрес для событий выбранных типов. Тело запроса подписывается HMAC-SHA256: заголовок X-Webhook-Signature = "sha256=" + hex(HMAC(secret, X-Webhook-Timestamp + "." + body)). Секрет возвращается только в этом ответе
+// @Tags Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body request.CreateWebhookRequest true "Адрес и типы событий"
+// @Success 201 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/admin/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req request.CreateWebhookRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	endpoint, err := h.webhookService.CreateEndpoint(r.Context(), actorID, req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	data := endpoint.ToResponse()
+	data.Secret = endpoint.Secret
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"message": "webhook created",
+	}, http.StatusCreated)
+}
+
+// GetWebhook
+// @Summary Получение webhook
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID webhook"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/admin/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.webhookService.GetEndpoint(r.Context(), id)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    endpoint.ToResponse(),
+	}, http.StatusOK)
+}
+
+// UpdateWebhook
+// @Summary Изменение webhook
+// @Description Меняет адрес, типы событий, описание или включает и выключает webhook. Отсутствующие поля не меняются
+// @Tags Webhooks
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path int true "ID webhook"
+// @Param request body request.UpdateWebhookRequest true "Новые данные"
+// @Success 200 {object} map[string]interface{}
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Failure 422 {object} responce.Problem
+// @Router /api/v1/admin/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	var req request.UpdateWebhookRequest
+	if !response.DecodeJSON(w, r, &req) {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	endpoint, err := h.webhookService.UpdateEndpoint(r.Context(), actorID, id, req)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    endpoint.ToResponse(),
+		"message": "webhook updated",
+	}, http.StatusOK)
+}
+
+// DeleteWebhook
+// @Summary Удаление webhook
+// @Description Удаляет webhook вместе с журналом доставок
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID webhook"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/admin/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.webhookService.DeleteEndpoint(r.Context(), actorID, id); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"message": "webhook deleted",
+	}, http.StatusOK)
+}
+
+// RotateSecret
+// @Summary Ротация секрета webhook
+// @Description Генерирует новый секрет подписи и возвращает его. Старый секрет перестает действовать сразу
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID webhook"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/admin/webhooks/{id}/rotate-secret [post]
+func (h *WebhookHandler) RotateSecret(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	endpoint, err := h.webhookService.RotateSecret(r.Context(), actorID, id)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	data := endpoint.ToResponse()
+	data.Secret = endpoint.Secret
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    data,
+		"message": "secret rotated",
+	}, http.StatusOK)
+}
+
+// ListDeliveries
+// @Summary Журнал доставок webhook
+// @Description Возвращает доставки от новых к старым
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID webhook"
+// @Param status query string false "pending, succeeded или dead"
+// @Param page query int false "Номер страницы" default(1)
+// @Param page_size query int false "Размер страницы" default(20)
+// @Success 200 {object} webhook.DeliveryListResponse
+// @Failure 400 {object} responce.Problem
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/admin/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+
+	page, pageSize, err := response.ParsePagination(r)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	status := r.URL.Query().Get("status")
+	if status != "" && !webhook.IsValidDeliveryStatus(status) {
+		response.Error(w, r, apperror.InvalidParameter("status"))
+		return
+	}
+
+	deliveries, total, err := h.webhookService.ListDeliveries(r.Context(), id, status, pageSize, (page-1)*pageSize)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	list := webhook.DeliveryListResponse{
+		Deliveries: make([]webhook.DeliveryResponse, 0, len(deliveries)),
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+	}
+	for i := range deliveries {
+		list.Deliveries = append(list.Deliveries, deliveries[i].ToResponse())
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    list,
+	}, http.StatusOK)
+}
+
+// GetDelivery
+// @Summary Доставка webhook
+// @Description Возвращает доставку с телом запроса и журналом попыток
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID webhook"
+// @Param delivery_id path int true "ID доставки"
+// @Success 200 {object} map[string]interface{}
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Router /api/v1/admin/webhooks/{id}/deliveries/{delivery_id} [get]
+func (h *WebhookHandler) GetDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathID(w, r, "delivery_id")
+	if !ok {
+		return
+	}
+
+	delivery, attempts, err := h.webhookService.GetDelivery(r.Context(), id, deliveryID)
+	if err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	data := delivery.ToResponse()
+	data.Payload = delivery.Payload
+	data.AttemptLog = make([]webhook.AttemptResponse, 0, len(attempts))
+	for i := range attempts {
+		data.AttemptLog = append(data.AttemptLog, attempts[i].ToResponse())
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"data":    data,
+	}, http.StatusOK)
+}
+
+// RetryDelivery
+// @Summary Повтор доставки webhook
+// @Description Возвращает доставку в очередь со сброшенным счетчиком попыток, в том числе из статуса dead
+// @Tags Webhooks
+// @Security BearerAuth
+// @Produce json
+// @Param id path int true "ID webhook"
+// @Param delivery_id path int true "ID доставки"
+// @Success 202 {object} map[string]interface{}
+// @Failure 403 {object} responce.Problem
+// @Failure 404 {object} responce.Problem
+// @Failure 409 {object} responce.Problem
+// @Router /api/v1/admin/webhooks/{id}/deliveries/{delivery_id}/retry [post]
+func (h *WebhookHandler) RetryDelivery(w http.ResponseWriter, r *http.Request) {
+	id, ok := pathID(w, r, "id")
+	if !ok {
+		return
+	}
+	deliveryID, ok := pathID(w, r, "delivery_id")
+	if !ok {
+		return
+	}
+
+	actorID, _ := middleware.GetUserIDFromContext(r.Context())
+
+	if err := h.webhookService.RetryDelivery(r.Context(), actorID, id, deliveryID); err != nil {
+		response.Error(w, r, err)
+		return
+	}
+
+	response.JSON(w, map[string]interface{}{
+		"success": true,
+		"message": "delivery queued",
+	}, http.StatusAccepted)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int64, bool) {
+	id, err := strconv.ParseInt(mux.Vars(r)[name], 10, 64)
+	if err != nil || id <= 0 {
+		response.Error(w, r, apperror.InvalidParameter(name))
+		return 0, false
+	}
+
+	return id, true
+}
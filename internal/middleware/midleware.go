@@ -2,20 +2,46 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
+	"errors"
 	"log"
 	"net/http"
+	"net/url"
 	"strings"
 	"time"
 
-	"auth_service/internal/handler/auth"
+	"auth_service/internal/config"
+	"auth_service/internal/handler/response"
+	"auth_service/internal/model/role"
+	"auth_service/internal/model/user"
+	rolerepo "auth_service/internal/repository/role"
 	tokenrepo "auth_service/internal/repository/token"
 	userrepo "auth_service/internal/repository/user"
+	"auth_service/internal/storage/blob"
+	"auth_service/pkg/apperror"
+	"auth_service/pkg/i18n"
 	"auth_service/pkg/jwt"
+	"auth_service/pkg/requestinfo"
 )
 
 type contextKey string
 
-const userIDKey contextKey = "user_id"
+const (
+	userIDKey contextKey = "user_id"
+	rolesKey  contextKey = "roles"
+)
+
+var (
+	ErrAuthorizationRequired = apperror.New(apperror.KindUnauthorized, "authorization_required", "authorization header required")
+	ErrAuthorizationFormat   = apperror.New(apperror.KindUnauthorized, "invalid_authorization_format", "invalid authorization format")
+	ErrInvalidToken          = apperror.New(apperror.KindUnauthorized, "invalid_token", "invalid or expired token")
+	ErrTokenRevoked          = apperror.New(apperror.KindUnauthorized, "token_revoked", "token has been revoked")
+	ErrImpersonationDenied   = apperror.New(apperror.KindForbidden, "impersonation_not_allowed", "not allowed with an impersonation token")
+	ErrServiceUnauthorized   = apperror.New(apperror.KindUnauthorized, "service_unauthorized", "valid service token required")
+)
+
+// ServiceTokenHeader — заголовок с токеном сервиса для внутреннего API
+const ServiceTokenHeader = "X-Service-Token"
 
 func AuthMiddleware(userRepo *userrepo.UserRepository, tokenRepo *tokenrepo.TokenRepository) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -24,52 +50,230 @@ func AuthMiddleware(userRepo *userrepo.UserRepository, tokenRepo *tokenrepo.Toke
 			if r.URL.Path == "/api/v1/auth/signup" ||
 				r.URL.Path == "/api/v1/auth/signin" ||
 				r.URL.Path == "/api/v1/auth/refresh" ||
-				r.URL.Path == "/health" {
+				r.URL.Path == "/api/v1/auth/restore" ||
+				r.URL.Path == "/api/v1/auth/restore/confirm" ||
+				r.URL.Path == "/health" ||
+				isInternalPath(r.URL.Path) ||
+				isBlobPath(r.URL.Path) {
 				next.ServeHTTP(w, r)
 				return
 			}
 
 			authHeader := r.Header.Get("Authorization")
 			if authHeader == "" {
-				auth.ErrorResponse(w, "authorization header required", http.StatusUnauthorized)
+				response.Error(w, r, ErrAuthorizationRequired)
 				return
 			}
 
 			parts := strings.Split(authHeader, " ")
 			if len(parts) != 2 || parts[0] != "Bearer" {
-				auth.ErrorResponse(w, "invalid authorization format", http.StatusUnauthorized)
+				response.Error(w, r, ErrAuthorizationFormat)
 				return
 			}
 
-			token := parts[1]
-
-			claims, err := jwt.ValidateAccessToken(token)
+			claims, account, err := Authenticate(r.Context(), userRepo, tokenRepo, parts[1])
 			if err != nil {
-				auth.ErrorResponse(w, "invalid or expired token", http.StatusUnauthorized)
+				response.Error(w, r, err)
 				return
 			}
 
-			blacklisted, err := tokenRepo.IsTokenBlacklisted(r.Context(), token)
-			if err != nil {
-				auth.ErrorResponse(w, "internal server error", http.StatusInternalServerError)
-				return
+			ctx := r.Context()
+			if r.Header.Get("Accept-Language") == "" && account.Locale.Valid {
+				if locale, ok := i18n.Normalize(account.Locale.String); ok {
+					ctx = i18n.WithLocale(ctx, locale)
+					r = r.WithContext(ctx)
+				}
 			}
-			if blacklisted {
-				auth.ErrorResponse(w, "token is blacklisted", http.StatusUnauthorized)
+
+			if err := account.CheckStatus(time.Now()); err != nil {
+				response.Error(w, r, err)
 				return
 			}
 
-			ctx := context.WithValue(r.Context(), userIDKey, claims.UserID)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			next.ServeHTTP(w, r.WithContext(WithIdentity(ctx, claims)))
 		})
 	}
 }
 
+// Authenticate проверяет access токен: подпись и срок, черный список, удаление аккаунта
+// и отзыв всех сессий. Статус аккаунта не проверяется — вызывающий сначала выбирает
+// язык ответа по профилю, затем вызывает CheckStatus. Используется и gRPC сервером.
+func Authenticate(ctx context.Context, userRepo *userrepo.UserRepository, tokenRepo *tokenrepo.TokenRepository, token string) (*jwt.Claims, *user.User, error) {
+	claims, err := jwt.ValidateAccessToken(token)
+	if err != nil {
+		return nil, nil, ErrInvalidToken
+	}
+
+	blacklisted, err := tokenRepo.IsTokenBlacklisted(ctx, token)
+	if err != nil {
+		return nil, nil, err
+	}
+	if blacklisted {
+		return nil, nil, ErrTokenRevoked
+	}
+
+	account, err := userRepo.GetStatus(ctx, claims.UserID)
+	if errors.Is(err, userrepo.ErrUserNotFound) {
+		return nil, nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if account.IsDeleted {
+		return nil, nil, ErrInvalidToken
+	}
+
+	validAfter := tokensValidAfter(ctx, tokenRepo, account)
+	if !validAfter.IsZero() && (claims.IssuedAt == nil || claims.IssuedAt.Time.Before(validAfter)) {
+		return nil, nil, ErrTokenRevoked
+	}
+
+	return claims, account, nil
+}
+
+// WithIdentity сохраняет в контексте пользователя и роли из проверенного токена
+func WithIdentity(ctx context.Context, claims *jwt.Claims) context.Context {
+	ctx = context.WithValue(ctx, userIDKey, claims.UserID)
+	ctx = context.WithValue(ctx, rolesKey, claims.Roles)
+	if claims.IsImpersonation() {
+		ctx = requestinfo.WithImpersonator(ctx, claims.Act.UserID)
+	}
+	return ctx
+}
+
+// tokensValidAfter возвращает отметку отзыва токенов пользователя: из Redis,
+// а если ключа нет или Redis недоступен — из Postgres. Берется более поздняя из двух,
+// чтобы устаревший кэш не вернул к жизни токены, отозванные при сбое Redis.
+func tokensValidAfter(ctx context.Context, tokenRepo *tokenrepo.TokenRepository, account *user.User) time.Time {
+	var validAfter time.Time
+	if account.TokensValidAfter.Valid {
+		validAfter = account.TokensValidAfter.Time
+	}
+
+	cached, ok, err := tokenRepo.GetTokensValidAfter(ctx, account.ID)
+	if err != nil {
+		log.Printf("failed to get tokens valid after for user %d: %v", account.ID, err)
+		return validAfter
+	}
+	if ok && cached.After(validAfter) {
+		return cached
+	}
+
+	return validAfter
+}
+
 func GetUserIDFromContext(ctx context.Context) (int64, bool) {
 	userID, ok := ctx.Value(userIDKey).(int64)
 	return userID, ok
 }
 
+func GetRolesFromContext(ctx context.Context) []string {
+	roles, _ := ctx.Value(rolesKey).([]string)
+	return roles
+}
+
+// RequirePermission пропускает запрос, только если одна из ролей из access токена
+// дает указанное право. Роль superadmin имеет все права.
+func RequirePermission(roleRepo *rolerepo.RoleRepository, permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			roles := GetRolesFromContext(r.Context())
+			if len(roles) == 0 {
+				response.Error(w, r, apperror.ErrForbidden)
+				return
+			}
+
+			for _, name := range roles {
+				if name == role.RoleSuperAdmin {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			permissions, err := roleRepo.GetPermissionsByRoles(r.Context(), roles)
+			if err != nil {
+				response.Error(w, r, err)
+				return
+			}
+
+			for _, p := range permissions {
+				if p == permission {
+					next.ServeHTTP(w, r)
+					return
+				}
+			}
+
+			response.Error(w, r, apperror.ErrForbidden)
+		})
+	}
+}
+
+// ServiceAuthMiddleware пускает во внутренний API только сервисы, передавшие
+// в X-Service-Token один из токенов internal.servicetokens. Пустой список закрывает API.
+func ServiceAuthMiddleware(tokens []string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !ValidServiceToken(r.Header.Get(ServiceTokenHeader), tokens) {
+				response.Error(w, r, ErrServiceUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// ValidServiceToken сравнивает предъявленный токен с internal.servicetokens за постоянное время.
+// Общая проверка для HTTP и gRPC.
+func ValidServiceToken(presented string, tokens []string) bool {
+	if presented == "" {
+		return false
+	}
+
+	for _, token := range tokens {
+		if token != "" && subtle.ConstantTimeCompare([]byte(presented), []byte(token)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// DenyImpersonation запрещает маршрут для токенов имперсонации:
+// сотрудник не может менять учетные данные пользователя или удалять профиль.
+func DenyImpersonation(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := requestinfo.ImpersonatorFromContext(r.Context()); ok {
+			response.Error(w, r, ErrImpersonationDenied)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// RequestInfoMiddleware сохраняет в контексте IP, User-Agent и ID запроса для журнала аудита
+// и возвращает ID запроса клиенту в заголовке X-Request-ID.
+func RequestInfoMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		info := requestinfo.FromRequest(r)
+		w.Header().Set(requestinfo.RequestIDHeader, info.RequestID)
+
+		ctx := requestinfo.NewContext(r.Context(), info)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// LocaleMiddleware выбирает язык ответа по Accept-Language. Если заголовка нет,
+// AuthMiddleware подставляет язык из профиля пользователя.
+func LocaleMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale, ok := i18n.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+		if !ok {
+			locale = i18n.Default()
+		}
+
+		next.ServeHTTP(w, r.WithContext(i18n.WithLocale(r.Context(), locale)))
+	})
+}
+
 func LoggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
@@ -79,12 +283,13 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 		next.ServeHTTP(rw, r)
 
 		duration := time.Since(start)
-		log.Printf("[%s] %s %s %d %v",
+		log.Printf("[%s] %s %s %d %v %s",
 			r.Method,
 			r.URL.Path,
 			r.RemoteAddr,
 			rw.status,
 			duration,
+			requestinfo.FromContext(r.Context()).RequestID,
 		)
 	})
 }
@@ -92,7 +297,7 @@ func LoggingMiddleware(next http.Handler) http.Handler {
 func CORSMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, PATCH, DELETE, OPTIONS")
 		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-Requested-With")
 
 		if r.Method == "OPTIONS" {
@@ -113,3 +318,23 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.status = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// isInternalPath — внутренний API проверяет токен сервиса, а не пользователя
+func isInternalPath(path string) bool {
+	return strings.HasPrefix(path, "/internal/")
+}
+
+// isBlobPath — ссылки локального хранилища защищены подписью, а не токеном
+func isBlobPath(path string) bool {
+	backend := config.App.Storage.Backend
+	if backend != blob.BackendFilesystem && backend != blob.BackendMemory {
+		return false
+	}
+
+	prefix, err := url.Parse(config.App.Storage.PublicURL)
+	if err != nil || prefix.Path == "" || prefix.Path == "/" {
+		return false
+	}
+
+	return strings.HasPrefix(path, strings.TrimRight(prefix.Path, "/")+"/")
+}
@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ActionSignUp         = "auth.sign_up"
+	ActionSignIn         = "auth.sign_in"
+	ActionSignInFailed   = "auth.sign_in_failed"
+	ActionLogout         = "auth.logout"
+	ActionLogoutAll      = "auth.logout_all"
+	ActionTokensRefresh  = "auth.tokens_refresh"
+	ActionAccountRestore = "auth.account_restore"
+
+	ActionProfileUpdate = "profile.update"
+	ActionProfileDelete = "profile.delete"
+	ActionProfilePurge  = "profile.purge"
+	ActionPhotoUpload   = "profile.photo_upload"
+	ActionPhotoDelete   = "profile.photo_delete"
+
+	ActionPhoneChangeRequest = "profile.phone_change_request"
+	ActionPhoneChange        = "profile.phone_change"
+	ActionDataExport         = "profile.data_export"
+
+	ActionAdminUserUpdate     = "admin.user.update"
+	ActionAdminPasswordReset  = "admin.user.password_reset"
+	ActionAdminUserBlock      = "admin.user.block"
+	ActionAdminUserUnblock    = "admin.user.unblock"
+	ActionAdminUserRestore    = "admin.user.restore"
+	ActionAdminSessionsRevoke = "admin.user.sessions_revoke"
+	ActionAdminRoleAssign     = "admin.role.assign"
+	ActionAdminRoleRevoke     = "admin.role.revoke"
+	ActionAdminImpersonate    = "admin.user.impersonate"
+
+	ActionAdminWebhookCreate       = "admin.webhook.create"
+	ActionAdminWebhookUpdate       = "admin.webhook.update"
+	ActionAdminWebhookDelete       = "admin.webhook.delete"
+	ActionAdminWebhookRotateSecret = "admin.webhook.rotate_secret"
+	ActionAdminWebhookRetry        = "admin.webhook.delivery_retry"
+)
+
+// Event представляет запись журнала аудита
+// @Description Запись журнала аудита
+type Event struct {
+	// Уникальный идентификатор
+	// @Example 1
+	ID int64 `db:"id" json:"id"`
+
+	// Кто выполнил действие
+	// @Example 1
+	ActorID *int64 `db:"actor_id" json:"actor_id,omitempty"`
+
+	// Над кем выполнено действие
+	// @Example 2
+	SubjectID *int64 `db:"subject_id" json:"subject_id,omitempty"`
+
+	// Тип действия
+	// @Example admin.user.block
+	Action string `db:"action" json:"action"`
+
+	IP        *string         `db:"ip" json:"ip,omitempty"`
+	UserAgent *string         `db:"user_agent" json:"user_agent,omitempty"`
+	RequestID *string         `db:"request_id" json:"request_id,omitempty"`
+	Metadata  json.RawMessage `db:"metadata" json:"metadata,omitempty"`
+	CreatedAt time.Time       `db:"created_at" json:"created_at"`
+}
+
+// ToActivity возвращает представление события для владельца аккаунта,
+// без сведений о том, кто из сотрудников выполнил действие. IP и User-Agent
+// действий сотрудника, в том числе по токену имперсонации, не показываются.
+func (e *Event) ToActivity() Activity {
+	activity := Activity{
+		Action:    e.Action,
+		CreatedAt: e.CreatedAt,
+	}
+	if e.byStaff() {
+		return activity
+	}
+	if e.IP != nil {
+		activity.IP = *e.IP
+	}
+	if e.UserAgent != nil {
+		activity.UserAgent = *e.UserAgent
+	}
+	return activity
+}
+
+// byStaff сообщает, выполнил ли действие кто-то кроме владельца аккаунта:
+// администратор над пользователем или сотрудник по токену имперсонации.
+// События без actor_id (например, неудачный вход) выполнены от имени клиента.
+func (e *Event) byStaff() bool {
+	if e.ActorID != nil && (e.SubjectID == nil || *e.ActorID != *e.SubjectID) {
+		return true
+	}
+	if len(e.Metadata) == 0 {
+		return false
+	}
+
+	var metadata struct {
+		ImpersonatedBy *int64 `json:"impersonated_by"`
+	}
+	// Нечитаемые метаданные считаются признаком действия сотрудника: лучше скрыть лишнее
+	if err := json.Unmarshal(e.Metadata, &metadata); err != nil {
+		return true
+	}
+	return metadata.ImpersonatedBy != nil
+}
+
+// Activity представляет событие в истории активности пользователя
+// @Description Событие в истории активности пользователя
+type Activity struct {
+	// Тип действия
+	// @Example auth.sign_in
+	Action string `json:"action"`
+
+	// IP адрес клиента
+	// @Example 192.168.1.10
+	IP string `json:"ip,omitempty"`
+
+	// User-Agent клиента
+	// @Example Mozilla/5.0
+	UserAgent string `json:"user_agent,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Filter задает условия выборки событий. Пустые поля не участвуют в фильтрации.
+type Filter struct {
+	ActorID   *int64
+	SubjectID *int64
+	Action    string
+	From      *time.Time
+	To        *time.Time
+	Limit     int
+	Offset    int
+}
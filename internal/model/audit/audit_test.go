@@ -0,0 +1,46 @@
+package audit
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestToActivityHidesStaffClientInfo(t *testing.T) {
+	id := func(v int64) *int64 { return &v }
+
+	tests := []struct {
+		name      string
+		actorID   *int64
+		subjectID *int64
+		metadata  string
+		wantIP    bool
+	}{
+		{"own action", id(1), id(1), "", true},
+		{"failed sign in", nil, id(1), `{"reason":"wrong_password"}`, true},
+		{"admin action", id(2), id(1), "", false},
+		{"impersonated action", id(1), id(1), `{"impersonated_by":2}`, false},
+		{"unreadable metadata", id(1), id(1), `not json`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip, ua := "198.51.100.1", "Mozilla/5.0"
+			event := Event{
+				ActorID:   tt.actorID,
+				SubjectID: tt.subjectID,
+				Action:    ActionProfileUpdate,
+				IP:        &ip,
+				UserAgent: &ua,
+				Metadata:  json.RawMessage(tt.metadata),
+			}
+
+			activity := event.ToActivity()
+			if got := activity.IP != "" && activity.UserAgent != ""; got != tt.wantIP {
+				t.Fatalf("ip = %q, user agent = %q, want shown = %v", activity.IP, activity.UserAgent, tt.wantIP)
+			}
+			if !tt.wantIP && (activity.IP != "" || activity.UserAgent != "") {
+				t.Fatalf("ip = %q, user agent = %q, want both hidden", activity.IP, activity.UserAgent)
+			}
+		})
+	}
+}
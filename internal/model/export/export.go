@@ -0,0 +1,56 @@
+package export
+
+import "time"
+
+const (
+	StatusPending    = "pending"
+	StatusProcessing = "processing"
+	StatusReady      = "ready"
+	StatusFailed     = "failed"
+)
+
+// Job — задача выгрузки данных пользователя
+type Job struct {
+	ID          string
+	UserID      int64
+	Status      string
+	ObjectKey   string
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// IsActive — задача еще не завершена, повторный запрос вернет ее же
+func (j *Job) IsActive() bool {
+	return j.Status == StatusPending || j.Status == StatusProcessing
+}
+
+// JobResponse представляет состояние выгрузки
+// @Description Состояние выгрузки данных аккаунта
+type JobResponse struct {
+	// Идентификатор задачи
+	// @Example 0b8e3c1a-7f0e-4a47-9d5e-2f7a5b1c9e11
+	ID string `json:"id"`
+
+	// Статус: pending, processing, ready, failed
+	// @Example ready
+	Status string `json:"status"`
+
+	// Ссылка на ZIP архив, есть только в статусе ready
+	DownloadURL string `json:"download_url,omitempty"`
+
+	// Срок действия ссылки
+	DownloadExpiresAt *time.Time `json:"download_expires_at,omitempty"`
+
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+func (j *Job) ToResponse() JobResponse {
+	return JobResponse{
+		ID:          j.ID,
+		Status:      j.Status,
+		CreatedAt:   j.CreatedAt,
+		CompletedAt: j.CompletedAt,
+	}
+}
@@ -0,0 +1,126 @@
+package outbox
+
+import (
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"auth_service/internal/model/user"
+)
+
+const (
+	EventUserCreated      = "user.created"
+	EventUserUpdated      = "user.updated"
+	EventUserDeleted      = "user.deleted"
+	EventUserPhotoChanged = "user.photo_changed"
+	EventSessionRevoked   = "session.revoked"
+)
+
+// EventTypes — все типы событий, на которые можно подписаться
+var EventTypes = []string{
+	EventUserCreated,
+	EventUserUpdated,
+	EventUserDeleted,
+	EventUserPhotoChanged,
+	EventSessionRevoked,
+}
+
+func IsValidEventType(eventType string) bool {
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// Event — строка таблицы outbox_events. Пишется в одной транзакции с изменением
+// пользователя и публикуется relay-воркером как минимум один раз.
+type Event struct {
+	ID             int64           `db:"id"`
+	IdempotencyKey string          `db:"idempotency_key"`
+	Type           string          `db:"event_type"`
+	UserID         int64           `db:"user_id"`
+	Payload        json.RawMessage `db:"payload"`
+	Attempts       int             `db:"attempts"`
+	LastError      sql.NullString  `db:"last_error"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at"`
+	PublishedAt    sql.NullTime    `db:"published_at"`
+	CreatedAt      time.Time       `db:"created_at"`
+}
+
+// Message — событие в том виде, в котором его получают внешние системы.
+// ID не меняется между повторными доставками, по нему получатель отбрасывает дубли.
+type Message struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	UserID     int64           `json:"user_id"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+func (e *Event) ToMessage() Message {
+	return Message{
+		ID:         e.IdempotencyKey,
+		Type:       e.Type,
+		UserID:     e.UserID,
+		OccurredAt: e.CreatedAt,
+		Data:       e.Payload,
+	}
+}
+
+// UserData — данные событий user.created и user.updated
+type UserData struct {
+	ID          int64      `json:"id"`
+	Name        string     `json:"name"`
+	DisplayName string     `json:"display_name,omitempty"`
+	PhoneNumber string     `json:"phone_number"`
+	Email       string     `json:"email,omitempty"`
+	BirthDate   string     `json:"birth_date,omitempty"`
+	Locale      string     `json:"locale,omitempty"`
+	Timezone    string     `json:"timezone,omitempty"`
+	Bio         string     `json:"bio,omitempty"`
+	Status      string     `json:"status"`
+	StatusUntil *time.Time `json:"status_until,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func NewUserData(u *user.User) UserData {
+	data := UserData{
+		ID:          u.ID,
+		Name:        u.Name,
+		DisplayName: u.DisplayName.String,
+		PhoneNumber: u.PhoneNumber,
+		Email:       u.Email.String,
+		Locale:      u.Locale.String,
+		Timezone:    u.Timezone.String,
+		Bio:         u.Bio.String,
+		Status:      u.Status,
+		UpdatedAt:   u.UpdatedAt,
+	}
+	if u.BirthDate.Valid {
+		data.BirthDate = u.BirthDate.Time.Format(time.DateOnly)
+	}
+	if u.StatusUntil.Valid {
+		until := u.StatusUntil.Time
+		data.StatusUntil = &until
+	}
+	return data
+}
+
+// PhotoChangedData — данные события user.photo_changed. Ссылку на фото получатель
+// запрашивает сам: подписанные ссылки истекают раньше, чем событие может быть доставлено.
+type PhotoChangedData struct {
+	HasPhoto bool `json:"has_photo"`
+}
+
+// DeletedData — данные события user.deleted. Anonymized = true означает, что
+// льготный период истек и персональные данные стерты безвозвратно.
+type DeletedData struct {
+	Anonymized bool `json:"anonymized"`
+}
+
+// SessionRevokedData — данные события session.revoked
+type SessionRevokedData struct {
+	TokensValidAfter time.Time `json:"tokens_valid_after"`
+}
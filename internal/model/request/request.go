@@ -1,8 +1,13 @@
 package request
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // LoginRequest для входа
 type LoginRequest struct {
-	PhoneNumber string `json:"phone_number" validate:"required,startswith=+,min=11,max=15"`
+	PhoneNumber string `json:"phone_number" validate:"required,max=32"`
 	Password    string `json:"password" validate:"required,min=6,max=100"`
 }
 
@@ -15,7 +20,7 @@ type SignUpRequest struct {
 
 	// Номер телефона в международном формате
 	// @Example +79161234567
-	PhoneNumber string `json:"phone_number" validate:"required,startswith=+,min=11,max=15"`
+	PhoneNumber string `json:"phone_number" validate:"required,max=32"`
 
 	// Email адрес (опционально)
 	// @Example user@example.com
@@ -32,7 +37,165 @@ type UpdateProfileRequest struct {
 	Email string `json:"email" validate:"omitempty,email,max=255"`
 }
 
+// OptionalString — поле JSON Merge Patch (RFC 7396): отсутствующее поле не меняется,
+// null очищает значение, строка устанавливает новое.
+type OptionalString struct {
+	Set   bool
+	Null  bool
+	Value string
+}
+
+func (o *OptionalString) UnmarshalJSON(data []byte) error {
+	o.Set = true
+	if string(data) == "null" {
+		o.Null = true
+		o.Value = ""
+		return nil
+	}
+	o.Null = false
+	return json.Unmarshal(data, &o.Value)
+}
+
+// PatchProfileRequest для частичного обновления профиля (application/merge-patch+json)
+type PatchProfileRequest struct {
+	// @Example Иван Иванов
+	Name OptionalString `json:"name" swaggertype:"string"`
+
+	// @Example Ваня
+	DisplayName OptionalString `json:"display_name" swaggertype:"string"`
+
+	// @Example user@example.com
+	Email OptionalString `json:"email" swaggertype:"string"`
+
+	// Дата в формате YYYY-MM-DD
+	// @Example 1990-05-17
+	BirthDate OptionalString `json:"birth_date" swaggertype:"string"`
+
+	// Тег языка BCP 47
+	// @Example ru-RU
+	Locale OptionalString `json:"locale" swaggertype:"string"`
+
+	// Часовой пояс IANA
+	// @Example Europe/Moscow
+	Timezone OptionalString `json:"timezone" swaggertype:"string"`
+
+	// @Example Люблю горы и велосипед
+	Bio OptionalString `json:"bio" swaggertype:"string"`
+}
+
+// ChangePhoneRequest для запроса смены номера телефона
+type ChangePhoneRequest struct {
+	// Новый номер телефона, на него придет код подтверждения
+	// @Example +79161234568
+	NewPhoneNumber string `json:"new_phone_number" validate:"required,max=32"`
+
+	// Текущий пароль
+	// @Example secret123
+	Password string `json:"password" validate:"required"`
+}
+
+// ConfirmPhoneChangeRequest для подтверждения смены номера кодом из SMS
+type ConfirmPhoneChangeRequest struct {
+	// @Example 123456
+	Code string `json:"code" validate:"required"`
+}
+
+// RestoreAccountRequest для запроса восстановления удаленного аккаунта
+type RestoreAccountRequest struct {
+	// Номер телефона удаленного аккаунта, на него придет код подтверждения
+	// @Example +79161234567
+	PhoneNumber string `json:"phone_number" validate:"required,max=32"`
+
+	// Пароль аккаунта
+	// @Example secret123
+	Password string `json:"password" validate:"required,min=6,max=100"`
+}
+
+// ConfirmAccountRestoreRequest для подтверждения восстановления кодом из SMS
+type ConfirmAccountRestoreRequest struct {
+	// @Example +79161234567
+	PhoneNumber string `json:"phone_number" validate:"required,max=32"`
+
+	// @Example 123456
+	Code string `json:"code" validate:"required"`
+}
+
 // @Param request body db.RefreshTokenRequest true "Refresh токен"
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
+
+// AssignRoleRequest для назначения роли пользователю
+type AssignRoleRequest struct {
+	Role string `json:"role" validate:"required"`
+}
+
+// AdminUpdateUserRequest для изменения пользователя администратором
+type AdminUpdateUserRequest struct {
+	Name        string `json:"name" validate:"omitempty,min=2,max=100"`
+	PhoneNumber string `json:"phone_number" validate:"omitempty,max=32"`
+	Email       string `json:"email" validate:"omitempty,email,max=255"`
+}
+
+// CreateWebhookRequest для регистрации webhook
+type CreateWebhookRequest struct {
+	// Адрес получателя, http или https
+	// @Example https://billing.internal/hooks/users
+	URL string `json:"url" validate:"required,max=2048"`
+
+	// Типы событий: user.created, user.updated, user.deleted, user.photo_changed, session.revoked
+	// @Example ["user.created","user.deleted"]
+	EventTypes []string `json:"event_types" validate:"required,min=1"`
+
+	// @Example Биллинг
+	Description string `json:"description" validate:"omitempty,max=255"`
+}
+
+// UpdateWebhookRequest для изменения webhook. Отсутствующие поля не меняются
+type UpdateWebhookRequest struct {
+	// @Example https://billing.internal/hooks/users
+	URL string `json:"url" validate:"omitempty,max=2048"`
+
+	// @Example ["user.created","user.updated","user.deleted"]
+	EventTypes []string `json:"event_types" validate:"omitempty,min=1"`
+
+	// @Example Биллинг
+	Description *string `json:"description" validate:"omitempty,max=255"`
+
+	// Выключенный webhook не получает новых событий, ожидающие доставки приостанавливаются
+	// @Example true
+	IsActive *bool `json:"is_active"`
+}
+
+// BlockUserRequest для блокировки пользователя.
+// Status — suspended (по умолчанию) или banned; Until задает срок приостановки.
+type BlockUserRequest struct {
+	Status string     `json:"status" validate:"omitempty,oneof=suspended banned"`
+	Reason string     `json:"reason" validate:"omitempty,max=500"`
+	Until  *time.Time `json:"until,omitempty"`
+}
+
+// BatchUsersRequest для получения публичных профилей нескольких пользователей
+type BatchUsersRequest struct {
+	// ID пользователей, не больше 100 различных
+	// @Example [1, 2, 3]
+	IDs []int64 `json:"ids" validate:"required,min=1,dive,gt=0"`
+}
+
+// PhotoUploadURLRequest для получения presigned URL загрузки фото
+type PhotoUploadURLRequest struct {
+	// MIME тип файла: image/jpeg или image/png
+	// @Example image/jpeg
+	ContentType string `json:"content_type" validate:"required,oneof=image/jpeg image/png"`
+
+	// Точный размер файла в байтах
+	// @Example 524288
+	Size int64 `json:"size" validate:"required,min=1"`
+}
+
+// ConfirmPhotoUploadRequest для подтверждения загрузки фото по presigned URL
+type ConfirmPhotoUploadRequest struct {
+	// Ключ объекта из ответа на запрос presigned URL
+	// @Example users/1/uploads/0b8e3c1a-7f0e-4a47-9d5e-2f7a5b1c9e11.jpg
+	ObjectKey string `json:"object_key" validate:"required"`
+}
@@ -1,6 +1,8 @@
 package responce
 
 import (
+	"auth_service/internal/model/audit"
+	"auth_service/pkg/validation"
 	"time"
 )
 
@@ -15,6 +17,10 @@ type UserResponse struct {
 	// @Example Иван Иванов
 	Name string `json:"name"`
 
+	// Отображаемое имя
+	// @Example Ваня
+	DisplayName string `json:"display_name,omitempty"`
+
 	// Номер телефона
 	// @Example +79161234567
 	PhoneNumber string `json:"phone_number"`
@@ -23,10 +29,30 @@ type UserResponse struct {
 	// @Example user@example.com
 	Email string `json:"email,omitempty"`
 
-	// URL фотографии профиля
-	// @Example http://localhost:9000/user-photos/users/1/profile.jpg
+	// Временная (presigned) ссылка на фотографию профиля
+	// @Example http://localhost:9000/user-photos/users/1/profile.jpg?X-Amz-Expires=3600&X-Amz-Signature=...
 	PhotoURL string `json:"photo_url,omitempty"`
 
+	// Временные ссылки на миниатюры по размеру стороны в пикселях
+	// @Example {"64": "http://localhost:9000/user-photos/users/1/profile_64.jpg"}
+	PhotoThumbnails map[string]string `json:"photo_thumbnails,omitempty"`
+
+	// Дата рождения
+	// @Example 1990-05-17
+	BirthDate string `json:"birth_date,omitempty"`
+
+	// Язык интерфейса (BCP 47)
+	// @Example ru-RU
+	Locale string `json:"locale,omitempty"`
+
+	// Часовой пояс (IANA)
+	// @Example Europe/Moscow
+	Timezone string `json:"timezone,omitempty"`
+
+	// О себе
+	// @Example Люблю горы и велосипед
+	Bio string `json:"bio,omitempty"`
+
 	// Дата создания
 	// @Example 2024-12-09T01:00:00Z`
 	CreatedAt time.Time `json:"created_at"`
@@ -36,9 +62,100 @@ type UserResponse struct {
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// PublicUserResponse — карточка пользователя для других сервисов, без контактных данных
+// @Description Публичный профиль пользователя
+type PublicUserResponse struct {
+	// Уникальный идентификатор
+	// @Example 1
+	ID int64 `json:"id"`
+
+	// Имя пользователя
+	// @Example Иван Иванов
+	Name string `json:"name"`
+
+	// Отображаемое имя
+	// @Example Ваня
+	DisplayName string `json:"display_name,omitempty"`
+
+	// Временная (presigned) ссылка на фотографию профиля
+	// @Example http://localhost:9000/user-photos/users/1/profile.jpg?X-Amz-Expires=3600&X-Amz-Signature=...
+	PhotoURL string `json:"photo_url,omitempty"`
+
+	// Временные ссылки на миниатюры по размеру стороны в пикселях
+	// @Example {"64": "http://localhost:9000/user-photos/users/1/profile_64.jpg"}
+	PhotoThumbnails map[string]string `json:"photo_thumbnails,omitempty"`
+}
+
+// AdminUserResponse представляет пользователя в админке
+// @Description Информация о пользователе для администратора
+type AdminUserResponse struct {
+	UserResponse
+
+	// Профиль удален
+	// @Example false
+	IsDeleted bool `json:"is_deleted"`
+
+	// Статус аккаунта: active, suspended, banned, pending_verification
+	// @Example active
+	Status string `json:"status"`
+
+	// Причина блокировки
+	// @Example spam
+	StatusReason string `json:"status_reason,omitempty"`
+
+	// Срок окончания приостановки
+	// @Example 2024-12-10T00:00:00Z
+	StatusUntil *time.Time `json:"status_until,omitempty"`
+}
+
+// UserListResponse для постраничного списка пользователей
+type UserListResponse struct {
+	Users    []AdminUserResponse `json:"users"`
+	Total    int64               `json:"total"`
+	Page     int                 `json:"page"`
+	PageSize int                 `json:"page_size"`
+}
+
+// ActivityListResponse для истории активности пользователя
+type ActivityListResponse struct {
+	Events   []audit.Activity `json:"events"`
+	Total    int64            `json:"total"`
+	Page     int              `json:"page"`
+	PageSize int              `json:"page_size"`
+}
+
+// AuditEventListResponse для выборки из журнала аудита
+type AuditEventListResponse struct {
+	Events   []audit.Event `json:"events"`
+	Total    int64         `json:"total"`
+	Page     int           `json:"page"`
+	PageSize int           `json:"page_size"`
+}
+
 // UploadPhotoResponse для ответа с фото
 type UploadPhotoResponse struct {
-	PhotoURL string `json:"photo_url"`
+	PhotoURL        string            `json:"photo_url"`
+	PhotoThumbnails map[string]string `json:"photo_thumbnails,omitempty"`
+}
+
+// PhotoUploadURLResponse описывает, как загрузить фото напрямую в хранилище
+type PhotoUploadURLResponse struct {
+	// Presigned URL для загрузки
+	UploadURL string `json:"upload_url"`
+
+	// HTTP метод загрузки
+	// @Example PUT
+	Method string `json:"method"`
+
+	// Заголовки, которые клиент обязан передать без изменений
+	Headers map[string]string `json:"headers"`
+
+	// Ключ объекта для подтверждения загрузки
+	// @Example users/1/uploads/0b8e3c1a-7f0e-4a47-9d5e-2f7a5b1c9e11.jpg
+	ObjectKey string `json:"object_key"`
+
+	// Срок действия URL
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type APIResponse struct {
@@ -48,8 +165,41 @@ type APIResponse struct {
 	Message string      `json:"message,omitempty"`
 }
 
-type ErrorResponse struct {
-	Success bool   `json:"success"`
-	Error   string `json:"error"`
-	Code    int    `json:"code,omitempty"`
+// Problem — тело ошибки в формате RFC 7807 (application/problem+json)
+// @Description Описание ошибки
+type Problem struct {
+	// URI типа ошибки, постоянный для каждого кода
+	// @Example urn:auth-service:problem:user_not_found
+	Type string `json:"type"`
+
+	// Краткое описание HTTP статуса
+	// @Example Not Found
+	Title string `json:"title"`
+
+	// HTTP статус
+	// @Example 404
+	Status int `json:"status"`
+
+	// Описание ошибки для человека
+	// @Example user not found
+	Detail string `json:"detail,omitempty"`
+
+	// Путь запроса, на котором произошла ошибка
+	// @Example /api/v1/admin/users/42
+	Instance string `json:"instance,omitempty"`
+
+	// Стабильный машиночитаемый код ошибки
+	// @Example user_not_found
+	Code string `json:"code"`
+
+	// ID запроса из заголовка X-Request-ID
+	RequestID string `json:"request_id,omitempty"`
+
+	// Ошибки полей запроса (для validation_failed)
+	Errors validation.ValidationErrors `json:"errors,omitempty"`
+
+	// Статус аккаунта (для account_suspended, account_banned)
+	Account map[string]interface{} `json:"account,omitempty"`
+
+	Details map[string]interface{} `json:"details,omitempty"`
 }
@@ -0,0 +1,38 @@
+package role
+
+import "time"
+
+const (
+	RoleSuperAdmin = "superadmin"
+	RoleAdmin      = "admin"
+	RoleSupport    = "support"
+)
+
+const (
+	PermUsersRead      = "users.read"
+	PermUsersWrite     = "users.write"
+	PermUsersBlock     = "users.block"
+	PermSessionsRevoke = "sessions.revoke"
+	PermRolesManage    = "roles.manage"
+	PermAuditRead      = "audit.read"
+	PermImpersonate    = "users.impersonate"
+	PermWebhooksManage = "webhooks.manage"
+)
+
+// Role представляет роль пользователя
+// @Description Роль пользователя
+type Role struct {
+	// Уникальный идентификатор
+	// @Example 1
+	ID int64 `db:"id" json:"id"`
+
+	// Название роли
+	// @Example admin
+	Name string `db:"name" json:"name"`
+
+	// Описание роли
+	// @Example User management
+	Description string `db:"description" json:"description,omitempty"`
+
+	CreatedAt time.Time `db:"created_at" json:"created_at"`
+}
@@ -1,33 +1,209 @@
 package user
 
 import (
+	"auth_service/internal/config"
 	"auth_service/internal/model/responce"
+	"auth_service/pkg/imageproc"
 	"database/sql"
+	"fmt"
+	"strconv"
 	"time"
 )
 
+const (
+	StatusActive              = "active"
+	StatusSuspended           = "suspended"
+	StatusBanned              = "banned"
+	StatusPendingVerification = "pending_verification"
+)
+
 type User struct {
-	ID          int64          `db:"id" json:"id"`
-	Name        string         `db:"name" json:"name" validate:"required,min=2,max=100"`
-	PhoneNumber string         `db:"phone_number" json:"phone_number" validate:"required,startswith=+,min=11,max=15"`
-	Email       sql.NullString `db:"email" json:"email,omitempty" validate:"omitempty,email,max=255"`
-	Password    string         `db:"password" json:"-" validate:"required,min=6,max=100"`
-	PhotoURL    sql.NullString `db:"photo_object" json:"photo_url,omitempty"`
-	IsDeleted   bool           `db:"is_deleted" json:"-"`
-	CreatedAt   time.Time      `db:"created_at" json:"created_at"`
-	UpdatedAt   time.Time      `db:"updated_at" json:"updated_at"`
+	ID           int64          `db:"id" json:"id"`
+	Name         string         `db:"name" json:"name" validate:"required,min=2,max=100"`
+	PhoneNumber  string         `db:"phone_number" json:"phone_number" validate:"required,startswith=+,min=11,max=15"`
+	Email        sql.NullString `db:"email" json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Password     string         `db:"password" json:"-" validate:"required,min=6,max=100"`
+	PhotoObject  sql.NullString `db:"photo_object" json:"-"`
+	DisplayName  sql.NullString `db:"display_name" json:"display_name,omitempty"`
+	BirthDate    sql.NullTime   `db:"birth_date" json:"birth_date,omitempty"`
+	Locale       sql.NullString `db:"locale" json:"locale,omitempty"`
+	Timezone     sql.NullString `db:"timezone" json:"timezone,omitempty"`
+	Bio          sql.NullString `db:"bio" json:"bio,omitempty"`
+	IsDeleted    bool           `db:"is_deleted" json:"-"`
+	DeletedAt    sql.NullTime   `db:"deleted_at" json:"-"`
+	AnonymizedAt sql.NullTime   `db:"anonymized_at" json:"-"`
+	Status       string         `db:"status" json:"-"`
+	StatusReason sql.NullString `db:"status_reason" json:"-"`
+	StatusUntil  sql.NullTime   `db:"status_until" json:"-"`
+	// TokensValidAfter — access токены, выданные раньше, отозваны
+	TokensValidAfter sql.NullTime `db:"tokens_valid_after" json:"-"`
+	CreatedAt        time.Time    `db:"created_at" json:"created_at"`
+	UpdatedAt        time.Time    `db:"updated_at" json:"updated_at"`
 }
 
 func (u *User) ToResponse() responce.UserResponse {
-	return responce.UserResponse{
-		ID:          u.ID,
-		Name:        u.Name,
-		PhoneNumber: u.PhoneNumber,
-		Email:       u.Email.String,
-		PhotoURL:    u.PhotoURL.String,
-		CreatedAt:   u.CreatedAt,
-		UpdatedAt:   u.UpdatedAt,
+	resp := responce.UserResponse{
+		ID:              u.ID,
+		Name:            u.Name,
+		DisplayName:     u.DisplayName.String,
+		PhoneNumber:     u.PhoneNumber,
+		Email:           u.Email.String,
+		PhotoURL:        signPhotoURL(u.PhotoObject.String),
+		PhotoThumbnails: PhotoThumbnails(u.PhotoObject.String),
+		Locale:          u.Locale.String,
+		Timezone:        u.Timezone.String,
+		Bio:             u.Bio.String,
+		CreatedAt:       u.CreatedAt,
+		UpdatedAt:       u.UpdatedAt,
+	}
+	if u.BirthDate.Valid {
+		resp.BirthDate = u.BirthDate.Time.Format(time.DateOnly)
+	}
+	return resp
+}
+
+// ToPublicResponse возвращает карточку пользователя без контактных данных
+func (u *User) ToPublicResponse() responce.PublicUserResponse {
+	return responce.PublicUserResponse{
+		ID:              u.ID,
+		Name:            u.Name,
+		DisplayName:     u.DisplayName.String,
+		PhotoURL:        signPhotoURL(u.PhotoObject.String),
+		PhotoThumbnails: PhotoThumbnails(u.PhotoObject.String),
+	}
+}
+
+// photoURLSigner превращает ключ объекта в ссылку для клиента.
+// Задается при старте приложения, см. SetPhotoURLSigner.
+var photoURLSigner func(objectKey string) string
+
+// SetPhotoURLSigner задает функцию, выдающую временные ссылки на фото.
+// В базе хранится только ключ объекта, поэтому смена домена хранилища
+// не требует переписывать строки.
+func SetPhotoURLSigner(signer func(objectKey string) string) {
+	photoURLSigner = signer
+}
+
+func signPhotoURL(objectKey string) string {
+	if objectKey == "" || photoURLSigner == nil {
+		return ""
+	}
+	return photoURLSigner(objectKey)
+}
+
+// PhotoThumbnails возвращает ссылки на миниатюры, которые сохраняются рядом с оригиналом
+func PhotoThumbnails(objectKey string) map[string]string {
+	if objectKey == "" || len(config.App.Photo.ThumbnailSizes) == 0 {
+		return nil
+	}
+
+	thumbnails := make(map[string]string, len(config.App.Photo.ThumbnailSizes))
+	for _, size := range config.App.Photo.ThumbnailSizes {
+		thumbnails[strconv.Itoa(size)] = signPhotoURL(imageproc.ThumbnailKey(objectKey, size))
+	}
+	return thumbnails
+}
+
+// PhotoKeys возвращает ключи текущего фото пользователя и его миниатюр
+func (u *User) PhotoKeys() []string {
+	return PhotoObjectKeys(u.PhotoObject.String)
+}
+
+// PhotoObjectKeys возвращает ключ оригинала и ключи всех его миниатюр
+func PhotoObjectKeys(objectKey string) []string {
+	if objectKey == "" {
+		return nil
+	}
+
+	keys := []string{objectKey}
+	for _, size := range config.App.Photo.ThumbnailSizes {
+		keys = append(keys, imageproc.ThumbnailKey(objectKey, size))
 	}
+	return keys
+}
+
+func (u *User) ToAdminResponse() responce.AdminUserResponse {
+	resp := responce.AdminUserResponse{
+		UserResponse: u.ToResponse(),
+		IsDeleted:    u.IsDeleted,
+		Status:       u.EffectiveStatus(time.Now()),
+		StatusReason: u.StatusReason.String,
+	}
+	if u.StatusUntil.Valid {
+		resp.StatusUntil = &u.StatusUntil.Time
+	}
+	return resp
+}
+
+// EffectiveStatus возвращает статус с учетом истекшей приостановки:
+// приостановка с прошедшим status_until считается снятой.
+func (u *User) EffectiveStatus(now time.Time) string {
+	if u.Status == StatusSuspended && u.StatusUntil.Valid && !u.StatusUntil.Time.After(now) {
+		return StatusActive
+	}
+	if u.Status == "" {
+		return StatusActive
+	}
+	return u.Status
+}
+
+// CheckStatus возвращает *StatusError, если пользователю запрещен вход.
+func (u *User) CheckStatus(now time.Time) error {
+	status := u.EffectiveStatus(now)
+	if status == StatusActive {
+		return nil
+	}
+
+	err := &StatusError{Status: status, Reason: u.StatusReason.String}
+	if status == StatusSuspended && u.StatusUntil.Valid {
+		err.Until = &u.StatusUntil.Time
+	}
+	return err
+}
+
+// StatusError описывает причину, по которой аккаунт не может быть использован
+type StatusError struct {
+	Status string
+	Reason string
+	Until  *time.Time
+}
+
+func (e *StatusError) Error() string {
+	switch e.Status {
+	case StatusSuspended:
+		if e.Until != nil {
+			return fmt.Sprintf("account is suspended until %s", e.Until.UTC().Format(time.RFC3339))
+		}
+		return "account is suspended"
+	case StatusBanned:
+		return "account is banned"
+	case StatusPendingVerification:
+		return "account is pending verification"
+	default:
+		return "account is not active"
+	}
+}
+
+func IsValidStatus(status string) bool {
+	switch status {
+	case StatusActive, StatusSuspended, StatusBanned, StatusPendingVerification:
+		return true
+	}
+	return false
+}
+
+// ListFilter задает условия выборки пользователей для админки.
+// Пустые поля не участвуют в фильтрации.
+type ListFilter struct {
+	PhoneNumber string
+	Email       string
+	Name        string
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	IsDeleted   *bool
+	Limit       int
+	Offset      int
 }
 
 type Tokens struct {
@@ -0,0 +1,236 @@
+package webhook
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+const (
+	DeliveryPending   = "pending"
+	DeliverySucceeded = "succeeded"
+	DeliveryDead      = "dead"
+)
+
+// IsValidDeliveryStatus проверяет статус из фильтра списка доставок
+func IsValidDeliveryStatus(status string) bool {
+	switch status {
+	case DeliveryPending, DeliverySucceeded, DeliveryDead:
+		return true
+	}
+	return false
+}
+
+// EventTypes — список типов событий, в базе хранится как JSONB массив
+type EventTypes []string
+
+func (t EventTypes) Value() (driver.Value, error) {
+	if t == nil {
+		t = EventTypes{}
+	}
+	return json.Marshal([]string(t))
+}
+
+func (t *EventTypes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, (*[]string)(t))
+	case string:
+		return json.Unmarshal([]byte(v), (*[]string)(t))
+	case nil:
+		*t = EventTypes{}
+		return nil
+	default:
+		return errors.New("unsupported event_types value")
+	}
+}
+
+// Endpoint — адрес, на который отправляются события выбранных типов
+type Endpoint struct {
+	ID          int64          `db:"id"`
+	URL         string         `db:"url"`
+	Secret      string         `db:"secret"`
+	EventTypes  EventTypes     `db:"event_types"`
+	Description sql.NullString `db:"description"`
+	IsActive    bool           `db:"is_active"`
+	CreatedBy   sql.NullInt64  `db:"created_by"`
+	CreatedAt   time.Time      `db:"created_at"`
+	UpdatedAt   time.Time      `db:"updated_at"`
+}
+
+// EndpointResponse представляет webhook без секрета
+// @Description Зарегистрированный webhook
+type EndpointResponse struct {
+	// @Example 1
+	ID int64 `json:"id"`
+
+	// @Example https://billing.internal/hooks/users
+	URL string `json:"url"`
+
+	// Типы событий, на которые подписан webhook
+	// @Example ["user.created","user.deleted"]
+	EventTypes []string `json:"event_types"`
+
+	// @Example Биллинг
+	Description string `json:"description,omitempty"`
+
+	IsActive  bool      `json:"is_active"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Секрет подписи. Возвращается только при создании и ротации
+	// @Example whsec_5f2b...
+	Secret string `json:"secret,omitempty"`
+}
+
+func (e *Endpoint) ToResponse() EndpointResponse {
+	eventTypes := []string(e.EventTypes)
+	if eventTypes == nil {
+		eventTypes = []string{}
+	}
+	return EndpointResponse{
+		ID:          e.ID,
+		URL:         e.URL,
+		EventTypes:  eventTypes,
+		Description: e.Description.String,
+		IsActive:    e.IsActive,
+		CreatedAt:   e.CreatedAt,
+		UpdatedAt:   e.UpdatedAt,
+	}
+}
+
+// Delivery — отправка одного события на один webhook
+type Delivery struct {
+	ID             int64           `db:"id"`
+	EndpointID     int64           `db:"endpoint_id"`
+	EventID        string          `db:"event_id"`
+	EventType      string          `db:"event_type"`
+	Payload        json.RawMessage `db:"payload"`
+	Status         string          `db:"status"`
+	Attempts       int             `db:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at"`
+	LastStatusCode sql.NullInt64   `db:"last_status_code"`
+	LastError      sql.NullString  `db:"last_error"`
+	DeliveredAt    sql.NullTime    `db:"delivered_at"`
+	CreatedAt      time.Time       `db:"created_at"`
+	UpdatedAt      time.Time       `db:"updated_at"`
+
+	// Заполняются только при выборке доставок для отправки
+	EndpointURL    string `db:"endpoint_url"`
+	EndpointSecret string `db:"endpoint_secret"`
+}
+
+// Attempt — запись журнала попыток доставки
+type Attempt struct {
+	ID         int64          `db:"id" json:"id"`
+	DeliveryID int64          `db:"delivery_id" json:"-"`
+	StatusCode sql.NullInt64  `db:"status_code" json:"-"`
+	Error      sql.NullString `db:"error" json:"-"`
+	Response   sql.NullString `db:"response" json:"-"`
+	DurationMS int            `db:"duration_ms" json:"duration_ms"`
+	CreatedAt  time.Time      `db:"created_at" json:"created_at"`
+}
+
+// AttemptResponse представляет попытку доставки
+// @Description Попытка доставки webhook
+type AttemptResponse struct {
+	// HTTP статус ответа получателя, нет при сетевой ошибке
+	// @Example 503
+	StatusCode *int64 `json:"status_code,omitempty"`
+
+	// @Example webhook responded with 503 Service Unavailable
+	Error string `json:"error,omitempty"`
+
+	// Начало тела ответа получателя
+	Response string `json:"response,omitempty"`
+
+	// @Example 120
+	DurationMS int       `json:"duration_ms"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (a *Attempt) ToResponse() AttemptResponse {
+	resp := AttemptResponse{
+		Error:      a.Error.String,
+		Response:   a.Response.String,
+		DurationMS: a.DurationMS,
+		CreatedAt:  a.CreatedAt,
+	}
+	if a.StatusCode.Valid {
+		code := a.StatusCode.Int64
+		resp.StatusCode = &code
+	}
+	return resp
+}
+
+// DeliveryResponse представляет доставку события
+// @Description Доставка события на webhook
+type DeliveryResponse struct {
+	// @Example 42
+	ID int64 `json:"id"`
+
+	// ID события, совпадает с заголовком Idempotency-Key
+	// @Example 0b8e3c1a-7f0e-4a47-9d5e-2f7a5b1c9e11
+	EventID string `json:"event_id"`
+
+	// @Example user.updated
+	EventType string `json:"event_type"`
+
+	// Статус: pending, succeeded, dead
+	// @Example pending
+	Status string `json:"status"`
+
+	// @Example 3
+	Attempts int `json:"attempts"`
+
+	// Время следующей попытки для статуса pending
+	NextAttemptAt *time.Time `json:"next_attempt_at,omitempty"`
+
+	// @Example 503
+	LastStatusCode *int64 `json:"last_status_code,omitempty"`
+
+	LastError   string     `json:"last_error,omitempty"`
+	DeliveredAt *time.Time `json:"delivered_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+
+	// Тело запроса, есть только в ответе на запрос одной доставки
+	Payload json.RawMessage `json:"payload,omitempty" swaggertype:"object"`
+
+	// Журнал попыток, есть только в ответе на запрос одной доставки
+	AttemptLog []AttemptResponse `json:"attempt_log,omitempty"`
+}
+
+func (d *Delivery) ToResponse() DeliveryResponse {
+	resp := DeliveryResponse{
+		ID:        d.ID,
+		EventID:   d.EventID,
+		EventType: d.EventType,
+		Status:    d.Status,
+		Attempts:  d.Attempts,
+		LastError: d.LastError.String,
+		CreatedAt: d.CreatedAt,
+	}
+	if d.Status == DeliveryPending {
+		next := d.NextAttemptAt
+		resp.NextAttemptAt = &next
+	}
+	if d.LastStatusCode.Valid {
+		code := d.LastStatusCode.Int64
+		resp.LastStatusCode = &code
+	}
+	if d.DeliveredAt.Valid {
+		delivered := d.DeliveredAt.Time
+		resp.DeliveredAt = &delivered
+	}
+	return resp
+}
+
+// DeliveryListResponse для списка доставок webhook
+type DeliveryListResponse struct {
+	Deliveries []DeliveryResponse `json:"deliveries"`
+	Total      int64              `json:"total"`
+	Page       int                `json:"page"`
+	PageSize   int                `json:"page_size"`
+}
@@ -0,0 +1,99 @@
+package auditrepo
+
+import (
+	"auth_service/internal/model/audit"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type Audit_Repository interface {
+	Create(ctx context.Context, event *audit.Event) error
+	List(ctx context.Context, filter audit.Filter) ([]audit.Event, int64, error)
+}
+
+type AuditRepository struct {
+	db *sqlx.DB
+}
+
+func NewAuditRepository(db *sqlx.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) Create(ctx context.Context, event *audit.Event) error {
+	query := `
+		INSERT INTO audit_events (actor_id, subject_id, action, ip, user_agent, request_id, metadata)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+
+	metadata := event.Metadata
+	if len(metadata) == 0 {
+		metadata = []byte("{}")
+	}
+
+	err := r.db.QueryRowxContext(ctx, query,
+		event.ActorID,
+		event.SubjectID,
+		event.Action,
+		event.IP,
+		event.UserAgent,
+		event.RequestID,
+		[]byte(metadata),
+	).Scan(&event.ID, &event.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("create audit event: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AuditRepository) List(ctx context.Context, filter audit.Filter) ([]audit.Event, int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorID != nil {
+		addCondition("actor_id = $%d", *filter.ActorID)
+	}
+	if filter.SubjectID != nil {
+		addCondition("subject_id = $%d", *filter.SubjectID)
+	}
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+	if filter.From != nil {
+		addCondition("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCondition("created_at < $%d", *filter.To)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM audit_events "+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count audit events: %w", err)
+	}
+
+	events := []audit.Event{}
+	query := fmt.Sprintf("SELECT * FROM audit_events %s ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", where, len(args)+1, len(args)+2)
+
+	err = r.db.SelectContext(ctx, &events, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list audit events: %w", err)
+	}
+
+	return events, total, nil
+}
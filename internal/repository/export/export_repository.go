@@ -0,0 +1,177 @@
+package exportrepo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"auth_service/internal/model/export"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type Export_Repository interface {
+	Create(ctx context.Context, job *export.Job, ttl time.Duration) error
+	Get(ctx context.Context, id string) (*export.Job, error)
+	GetLatest(ctx context.Context, userID int64) (*export.Job, error)
+	Update(ctx context.Context, job *export.Job) error
+	Delete(ctx context.Context, job *export.Job) error
+	DeleteAll(ctx context.Context, userID int64) error
+}
+
+type ExportRepository struct {
+	redisClient *redis.Client
+}
+
+func NewExportRepository(redisClient *redis.Client) *ExportRepository {
+	return &ExportRepository{redisClient: redisClient}
+}
+
+func jobKey(id string) string {
+	return "export:job:" + id
+}
+
+func latestKey(userID int64) string {
+	return fmt.Sprintf("export:latest:%d", userID)
+}
+
+// userJobsKey — множество ID всех задач пользователя, для удаления вместе с аккаунтом
+func userJobsKey(userID int64) string {
+	return fmt.Sprintf("export:user:%d", userID)
+}
+
+// Create сохраняет задачу и делает ее последней выгрузкой пользователя.
+// Обе записи живут ttl, после этого архив тоже удаляется из хранилища.
+func (r *ExportRepository) Create(ctx context.Context, job *export.Job, ttl time.Duration) error {
+	pipe := r.redisClient.TxPipeline()
+	pipe.HSet(ctx, jobKey(job.ID), jobFields(job))
+	pipe.Expire(ctx, jobKey(job.ID), ttl)
+	pipe.Set(ctx, latestKey(job.UserID), job.ID, ttl)
+	pipe.SAdd(ctx, userJobsKey(job.UserID), job.ID)
+	pipe.Expire(ctx, userJobsKey(job.UserID), ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to create export job: %w", err)
+	}
+
+	return nil
+}
+
+// Get возвращает nil, nil, если задачи нет или она истекла
+func (r *ExportRepository) Get(ctx context.Context, id string) (*export.Job, error) {
+	values, err := r.redisClient.HGetAll(ctx, jobKey(id)).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get export job: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	userID, _ := strconv.ParseInt(values["user_id"], 10, 64)
+	createdAt, _ := strconv.ParseInt(values["created_at"], 10, 64)
+
+	job := &export.Job{
+		ID:        id,
+		UserID:    userID,
+		Status:    values["status"],
+		ObjectKey: values["object_key"],
+		Error:     values["error"],
+		CreatedAt: time.Unix(createdAt, 0).UTC(),
+	}
+	if completedAt, err := strconv.ParseInt(values["completed_at"], 10, 64); err == nil && completedAt > 0 {
+		t := time.Unix(completedAt, 0).UTC()
+		job.CompletedAt = &t
+	}
+
+	return job, nil
+}
+
+// GetLatest возвращает последнюю выгрузку пользователя или nil, nil
+func (r *ExportRepository) GetLatest(ctx context.Context, userID int64) (*export.Job, error) {
+	id, err := r.redisClient.Get(ctx, latestKey(userID)).Result()
+	if err == redis.Nil {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest export job: %w", err)
+	}
+
+	return r.Get(ctx, id)
+}
+
+// Update сохраняет статус задачи, не продлевая срок ее жизни.
+// Истекшая задача не воскрешается.
+func (r *ExportRepository) Update(ctx context.Context, job *export.Job) error {
+	exists, err := r.redisClient.Exists(ctx, jobKey(job.ID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+	if exists == 0 {
+		return nil
+	}
+
+	if err := r.redisClient.HSet(ctx, jobKey(job.ID), jobFields(job)).Err(); err != nil {
+		return fmt.Errorf("failed to update export job: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ExportRepository) Delete(ctx context.Context, job *export.Job) error {
+	pipe := r.redisClient.TxPipeline()
+	pipe.Del(ctx, jobKey(job.ID))
+	pipe.Del(ctx, latestKey(job.UserID))
+	pipe.SRem(ctx, userJobsKey(job.UserID), job.ID)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete export job: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAll удаляет все задачи пользователя, в том числе еще не обработанные:
+// воркер не найдет задачу и не сохранит архив (см. ExportService.process).
+func (r *ExportRepository) DeleteAll(ctx context.Context, userID int64) error {
+	ids, err := r.redisClient.SMembers(ctx, userJobsKey(userID)).Result()
+	if err != nil {
+		return fmt.Errorf("failed to list export jobs: %w", err)
+	}
+
+	// Задачи, созданные до появления множества, известны только по ссылке на последнюю
+	latest, err := r.redisClient.Get(ctx, latestKey(userID)).Result()
+	if err != nil && err != redis.Nil {
+		return fmt.Errorf("failed to get latest export job: %w", err)
+	}
+	if latest != "" {
+		ids = append(ids, latest)
+	}
+
+	keys := []string{latestKey(userID), userJobsKey(userID)}
+	for _, id := range ids {
+		keys = append(keys, jobKey(id))
+	}
+
+	if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete export jobs: %w", err)
+	}
+
+	return nil
+}
+
+func jobFields(job *export.Job) map[string]interface{} {
+	completedAt := int64(0)
+	if job.CompletedAt != nil {
+		completedAt = job.CompletedAt.Unix()
+	}
+
+	return map[string]interface{}{
+		"user_id":      job.UserID,
+		"status":       job.Status,
+		"object_key":   job.ObjectKey,
+		"error":        job.Error,
+		"created_at":   job.CreatedAt.Unix(),
+		"completed_at": completedAt,
+	}
+}
@@ -0,0 +1,198 @@
+package otprepo
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// PhoneChange — ожидающая подтверждения смена номера телефона
+type PhoneChange struct {
+	NewPhone string
+	CodeHash string
+	Attempts int
+}
+
+// AccountRestore — ожидающее подтверждения восстановление удаленного аккаунта
+type AccountRestore struct {
+	CodeHash string
+	Attempts int
+}
+
+type OTP_Repository interface {
+	AcquireCooldown(ctx context.Context, userID int64, interval time.Duration) (bool, error)
+	StorePhoneChange(ctx context.Context, userID int64, newPhone, codeHash string, ttl time.Duration) error
+	GetPhoneChange(ctx context.Context, userID int64) (*PhoneChange, error)
+	IncrementPhoneChangeAttempts(ctx context.Context, userID int64) (int, error)
+	DeletePhoneChange(ctx context.Context, userID int64) error
+	AcquireRestoreCooldown(ctx context.Context, userID int64, interval time.Duration) (bool, error)
+	StoreAccountRestore(ctx context.Context, userID int64, codeHash string, ttl time.Duration) error
+	GetAccountRestore(ctx context.Context, userID int64) (*AccountRestore, error)
+	IncrementAccountRestoreAttempts(ctx context.Context, userID int64) (int, error)
+	DeleteAccountRestore(ctx context.Context, userID int64) error
+	DeleteAll(ctx context.Context, userID int64) error
+}
+
+type OTPRepository struct {
+	redisClient *redis.Client
+}
+
+func NewOTPRepository(redisClient *redis.Client) *OTPRepository {
+	return &OTPRepository{redisClient: redisClient}
+}
+
+// AcquireCooldown возвращает false, если код уже отправлялся в течение interval
+func (r *OTPRepository) AcquireCooldown(ctx context.Context, userID int64, interval time.Duration) (bool, error) {
+	key := fmt.Sprintf("otp:phone_change_cooldown:%d", userID)
+
+	ok, err := r.redisClient.SetNX(ctx, key, "1", interval).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to set otp cooldown: %w", err)
+	}
+
+	return ok, nil
+}
+
+func (r *OTPRepository) StorePhoneChange(ctx context.Context, userID int64, newPhone, codeHash string, ttl time.Duration) error {
+	key := fmt.Sprintf("otp:phone_change:%d", userID)
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key, "phone", newPhone, "code_hash", codeHash, "attempts", 0)
+	pipe.Expire(ctx, key, ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store phone change: %w", err)
+	}
+
+	return nil
+}
+
+// GetPhoneChange возвращает nil, nil, если смена номера не запрашивалась или код истек
+func (r *OTPRepository) GetPhoneChange(ctx context.Context, userID int64) (*PhoneChange, error) {
+	key := fmt.Sprintf("otp:phone_change:%d", userID)
+
+	values, err := r.redisClient.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get phone change: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	attempts, _ := strconv.Atoi(values["attempts"])
+
+	return &PhoneChange{
+		NewPhone: values["phone"],
+		CodeHash: values["code_hash"],
+		Attempts: attempts,
+	}, nil
+}
+
+func (r *OTPRepository) IncrementPhoneChangeAttempts(ctx context.Context, userID int64) (int, error) {
+	key := fmt.Sprintf("otp:phone_change:%d", userID)
+
+	attempts, err := r.redisClient.HIncrBy(ctx, key, "attempts", 1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment otp attempts: %w", err)
+	}
+
+	return int(attempts), nil
+}
+
+func (r *OTPRepository) DeletePhoneChange(ctx context.Context, userID int64) error {
+	key := fmt.Sprintf("otp:phone_change:%d", userID)
+
+	if err := r.redisClient.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to delete phone change: %w", err)
+	}
+
+	return nil
+}
+
+// AcquireRestoreCooldown возвращает false, если код восстановления уже отправлялся в течение interval
+func (r *OTPRepository) AcquireRestoreCooldown(ctx context.Context, userID int64, interval time.Duration) (bool, error) {
+	key := fmt.Sprintf("otp:account_restore_cooldown:%d", userID)
+
+	ok, err := r.redisClient.SetNX(ctx, key, "1", interval).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to set otp cooldown: %w", err)
+	}
+
+	return ok, nil
+}
+
+func (r *OTPRepository) StoreAccountRestore(ctx context.Context, userID int64, codeHash string, ttl time.Duration) error {
+	key := fmt.Sprintf("otp:account_restore:%d", userID)
+
+	pipe := r.redisClient.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.HSet(ctx, key, "code_hash", codeHash, "attempts", 0)
+	pipe.Expire(ctx, key, ttl)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to store account restore: %w", err)
+	}
+
+	return nil
+}
+
+// GetAccountRestore возвращает nil, nil, если восстановление не запрашивалось или код истек
+func (r *OTPRepository) GetAccountRestore(ctx context.Context, userID int64) (*AccountRestore, error) {
+	key := fmt.Sprintf("otp:account_restore:%d", userID)
+
+	values, err := r.redisClient.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account restore: %w", err)
+	}
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	attempts, _ := strconv.Atoi(values["attempts"])
+
+	return &AccountRestore{
+		CodeHash: values["code_hash"],
+		Attempts: attempts,
+	}, nil
+}
+
+func (r *OTPRepository) IncrementAccountRestoreAttempts(ctx context.Context, userID int64) (int, error) {
+	key := fmt.Sprintf("otp:account_restore:%d", userID)
+
+	attempts, err := r.redisClient.HIncrBy(ctx, key, "attempts", 1).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to increment otp attempts: %w", err)
+	}
+
+	return int(attempts), nil
+}
+
+func (r *OTPRepository) DeleteAccountRestore(ctx context.Context, userID int64) error {
+	key := fmt.Sprintf("otp:account_restore:%d", userID)
+
+	if err := r.redisClient.Del(ctx, key).Err(); err != nil {
+		return fmt.Errorf("failed to delete account restore: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAll удаляет все коды и ограничения пользователя
+func (r *OTPRepository) DeleteAll(ctx context.Context, userID int64) error {
+	keys := []string{
+		fmt.Sprintf("otp:phone_change:%d", userID),
+		fmt.Sprintf("otp:phone_change_cooldown:%d", userID),
+		fmt.Sprintf("otp:account_restore:%d", userID),
+		fmt.Sprintf("otp:account_restore_cooldown:%d", userID),
+	}
+
+	if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete otp state: %w", err)
+	}
+
+	return nil
+}
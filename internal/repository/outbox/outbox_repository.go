@@ -0,0 +1,119 @@
+package outboxrepo
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"auth_service/internal/model/outbox"
+
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type Outbox_Repository interface {
+	Claim(ctx context.Context, limit int, lease time.Duration) ([]outbox.Event, error)
+	MarkPublished(ctx context.Context, id int64) error
+	MarkFailed(ctx context.Context, id int64, reason string, nextAttemptAt time.Time) error
+	DeletePublished(ctx context.Context, before time.Time) (int64, error)
+}
+
+type OutboxRepository struct {
+	db *sqlx.DB
+}
+
+func NewOutboxRepository(db *sqlx.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Append добавляет событие в outbox. Вызывается внутри транзакции, изменяющей
+// пользователя: событие появляется тогда и только тогда, когда изменение зафиксировано.
+func Append(ctx context.Context, tx sqlx.ExecerContext, eventType string, userID int64, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s payload: %w", eventType, err)
+	}
+
+	query := `
+		INSERT INTO outbox_events (idempotency_key, event_type, user_id, payload)
+		VALUES ($1, $2, $3, $4)
+	`
+
+	if _, err := tx.ExecContext(ctx, query, uuid.NewString(), eventType, userID, payload); err != nil {
+		return fmt.Errorf("failed to append %s event: %w", eventType, err)
+	}
+
+	return nil
+}
+
+// RedactUser очищает данные уже записанных событий пользователя. Вызывается
+// при обезличивании: в user.created и user.updated лежат телефон и email.
+func RedactUser(ctx context.Context, tx sqlx.ExecerContext, userID int64) error {
+	if _, err := tx.ExecContext(ctx, `UPDATE outbox_events SET payload = '{}' WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to redact outbox events: %w", err)
+	}
+
+	return nil
+}
+
+// Claim забирает до limit готовых к отправке событий и откладывает их повторную
+// выдачу на lease. Несколько реплик не получат одно событие одновременно, а если
+// воркер упадет, не отметив результат, событие вернется в выборку после lease.
+func (r *OutboxRepository) Claim(ctx context.Context, limit int, lease time.Duration) ([]outbox.Event, error) {
+	var events []outbox.Event
+	query := `
+		UPDATE outbox_events SET next_attempt_at = $1
+		WHERE id IN (
+			SELECT id FROM outbox_events
+			WHERE published_at IS NULL AND next_attempt_at <= NOW()
+			ORDER BY id
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *
+	`
+
+	err := r.db.SelectContext(ctx, &events, query, time.Now().Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *OutboxRepository) MarkPublished(ctx context.Context, id int64) error {
+	query := `UPDATE outbox_events SET published_at = NOW(), last_error = NULL WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to mark outbox event published: %w", err)
+	}
+
+	return nil
+}
+
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id int64, reason string, nextAttemptAt time.Time) error {
+	query := `
+		UPDATE outbox_events
+		SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2
+		WHERE id = $3
+	`
+
+	if _, err := r.db.ExecContext(ctx, query, reason, nextAttemptAt, id); err != nil {
+		return fmt.Errorf("failed to mark outbox event failed: %w", err)
+	}
+
+	return nil
+}
+
+// DeletePublished удаляет доставленные события, опубликованные раньше before
+func (r *OutboxRepository) DeletePublished(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM outbox_events WHERE published_at IS NOT NULL AND published_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete published outbox events: %w", err)
+	}
+
+	return result.RowsAffected()
+}
@@ -0,0 +1,217 @@
+package rolerepo
+
+import (
+	"auth_service/internal/model/role"
+	"auth_service/pkg/apperror"
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrRoleNotFound    = apperror.New(apperror.KindNotFound, "role_not_found", "role not found")
+	ErrRoleNotAssigned = apperror.New(apperror.KindNotFound, "role_not_assigned", "role not assigned")
+	ErrLastRoleHolder  = apperror.New(apperror.KindConflict, "last_role_holder", "cannot revoke the role from its last holder")
+)
+
+type Role_Repository interface {
+	ListRoles(ctx context.Context) ([]role.Role, error)
+	GetByName(ctx context.Context, name string) (*role.Role, error)
+	GetUserRoles(ctx context.Context, userID int64) ([]string, error)
+	GetPermissionsByRoles(ctx context.Context, roles []string) ([]string, error)
+	AssignRole(ctx context.Context, userID int64, roleName string) error
+	RemoveRole(ctx context.Context, userID int64, roleName string) error
+	RemoveRoleUnlessLast(ctx context.Context, userID int64, roleName string) error
+	CountUsersWithRole(ctx context.Context, roleName string) (int64, error)
+}
+
+type RoleRepository struct {
+	db *sqlx.DB
+}
+
+func NewRoleRepository(db *sqlx.DB) *RoleRepository {
+	return &RoleRepository{db: db}
+}
+
+func (r *RoleRepository) ListRoles(ctx context.Context) ([]role.Role, error) {
+	roles := []role.Role{}
+	query := `SELECT id, name, COALESCE(description, '') AS description, created_at FROM roles ORDER BY id`
+
+	err := r.db.SelectContext(ctx, &roles, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (r *RoleRepository) GetByName(ctx context.Context, name string) (*role.Role, error) {
+	var role role.Role
+	query := `SELECT id, name, COALESCE(description, '') AS description, created_at FROM roles WHERE name = $1`
+
+	err := r.db.GetContext(ctx, &role, query, name)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get role by name: %w", err)
+	}
+
+	return &role, nil
+}
+
+func (r *RoleRepository) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	roles := []string{}
+	query := `
+		SELECT r.name
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE ur.user_id = $1
+		ORDER BY r.name
+	`
+
+	err := r.db.SelectContext(ctx, &roles, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	return roles, nil
+}
+
+func (r *RoleRepository) GetPermissionsByRoles(ctx context.Context, roles []string) ([]string, error) {
+	permissions := []string{}
+	if len(roles) == 0 {
+		return permissions, nil
+	}
+
+	query := `
+		SELECT DISTINCT p.name
+		FROM role_permissions rp
+		JOIN roles r ON r.id = rp.role_id
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE r.name = ANY($1)
+	`
+
+	err := r.db.SelectContext(ctx, &permissions, query, roles)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get permissions: %w", err)
+	}
+
+	return permissions, nil
+}
+
+func (r *RoleRepository) AssignRole(ctx context.Context, userID int64, roleName string) error {
+	query := `
+		INSERT INTO user_roles (user_id, role_id)
+		SELECT $1, id FROM roles WHERE name = $2
+		ON CONFLICT (user_id, role_id) DO NOTHING
+	`
+
+	existing, err := r.GetByName(ctx, roleName)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		return ErrRoleNotFound
+	}
+
+	_, err = r.db.ExecContext(ctx, query, userID, roleName)
+	if err != nil {
+		return fmt.Errorf("failed to assign role: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RoleRepository) RemoveRole(ctx context.Context, userID int64, roleName string) error {
+	query := `
+		DELETE FROM user_roles
+		WHERE user_id = $1 AND role_id = (SELECT id FROM roles WHERE name = $2)
+	`
+
+	result, err := r.db.ExecContext(ctx, query, userID, roleName)
+	if err != nil {
+		return fmt.Errorf("failed to remove role: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrRoleNotAssigned
+	}
+
+	return nil
+}
+
+// RemoveRoleUnlessLast снимает роль, только если у нее останется хотя бы один владелец.
+// Строки user_roles этой роли блокируются до конца транзакции, поэтому два
+// одновременных отзыва не могут оба увидеть двух владельцев и оставить ни одного.
+func (r *RoleRepository) RemoveRoleUnlessLast(ctx context.Context, userID int64, roleName string) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	holders := []int64{}
+	query := `
+		SELECT ur.user_id
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE r.name = $1
+		FOR UPDATE OF ur
+	`
+	if err := tx.SelectContext(ctx, &holders, query, roleName); err != nil {
+		return fmt.Errorf("failed to lock role holders: %w", err)
+	}
+
+	assigned := false
+	for _, id := range holders {
+		if id == userID {
+			assigned = true
+			break
+		}
+	}
+	if !assigned {
+		return ErrRoleNotAssigned
+	}
+	if len(holders) <= 1 {
+		return ErrLastRoleHolder
+	}
+
+	query = `
+		DELETE FROM user_roles
+		WHERE user_id = $1 AND role_id = (SELECT id FROM roles WHERE name = $2)
+	`
+	if _, err := tx.ExecContext(ctx, query, userID, roleName); err != nil {
+		return fmt.Errorf("failed to remove role: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit role removal: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RoleRepository) CountUsersWithRole(ctx context.Context, roleName string) (int64, error) {
+	var count int64
+	query := `
+		SELECT COUNT(*)
+		FROM user_roles ur
+		JOIN roles r ON r.id = ur.role_id
+		WHERE r.name = $1
+	`
+
+	err := r.db.GetContext(ctx, &count, query, roleName)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count users with role: %w", err)
+	}
+
+	return count, nil
+}
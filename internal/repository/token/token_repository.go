@@ -2,6 +2,7 @@ package tokenrepo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,12 +11,16 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+var ErrRefreshTokenNotFound = errors.New("refresh token not found")
+
 type Token_Repository interface {
 	StoreRefreshToken(ctx context.Context, userID int64, token string) error
 	GetRefreshToken(ctx context.Context, userID int64) (string, error)
 	DeleteRefreshToken(ctx context.Context, userID int64) error
 	StoreBlacklistedToken(ctx context.Context, token string, ttl time.Duration) error
 	IsTokenBlacklisted(ctx context.Context, token string) (bool, error)
+	SetTokensValidAfter(ctx context.Context, userID int64, validAfter time.Time) error
+	GetTokensValidAfter(ctx context.Context, userID int64) (time.Time, bool, error)
 }
 
 type TokenRepository struct {
@@ -44,7 +49,7 @@ func (r *TokenRepository) GetRefreshToken(ctx context.Context, userID int64) (st
 	token, err := r.redisClient.Get(ctx, key).Result()
 	if err != nil {
 		if err == redis.Nil {
-			return "", fmt.Errorf("refresh token not found")
+			return "", ErrRefreshTokenNotFound
 		}
 		return "", fmt.Errorf("failed to get refresh token: %w", err)
 	}
@@ -84,6 +89,35 @@ func (r *TokenRepository) IsTokenBlacklisted(ctx context.Context, token string)
 	return exists == 1, nil
 }
 
+// SetTokensValidAfter кэширует отметку отзыва. Ключ живет не дольше refresh токена:
+// после этого отозванные токены истекают сами, а отметка остается в Postgres.
+func (r *TokenRepository) SetTokensValidAfter(ctx context.Context, userID int64, validAfter time.Time) error {
+	key := fmt.Sprintf("tokens_valid_after:%d", userID)
+	ttl := parseDuration(config.App.JWT.RefreshTTL)
+
+	err := r.redisClient.Set(ctx, key, validAfter.Unix(), ttl).Err()
+	if err != nil {
+		return fmt.Errorf("failed to store tokens valid after: %w", err)
+	}
+
+	return nil
+}
+
+// GetTokensValidAfter возвращает false, если отметки в Redis нет
+func (r *TokenRepository) GetTokensValidAfter(ctx context.Context, userID int64) (time.Time, bool, error) {
+	key := fmt.Sprintf("tokens_valid_after:%d", userID)
+
+	seconds, err := r.redisClient.Get(ctx, key).Int64()
+	if err != nil {
+		if err == redis.Nil {
+			return time.Time{}, false, nil
+		}
+		return time.Time{}, false, fmt.Errorf("failed to get tokens valid after: %w", err)
+	}
+
+	return time.Unix(seconds, 0), true, nil
+}
+
 func parseDuration(durationStr string) time.Duration {
 	dur, err := time.ParseDuration(durationStr)
 	if err != nil {
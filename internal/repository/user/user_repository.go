@@ -1,14 +1,31 @@
 package userrepo
 
 import (
+	"auth_service/internal/config"
+	"auth_service/internal/model/outbox"
 	"auth_service/internal/model/user"
+	outboxrepo "auth_service/internal/repository/outbox"
+	webhookrepo "auth_service/internal/repository/webhook"
+	"auth_service/pkg/apperror"
+	"auth_service/pkg/phone"
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jmoiron/sqlx"
 )
 
+var (
+	ErrUserNotFound        = apperror.New(apperror.KindNotFound, "user_not_found", "user not found")
+	ErrDeletedUserNotFound = apperror.New(apperror.KindNotFound, "deleted_user_not_found", "deleted user not found")
+	ErrPhoneTaken          = apperror.New(apperror.KindConflict, "phone_number_taken", "phone number already in use")
+	ErrEmailTaken          = apperror.New(apperror.KindConflict, "email_taken", "email already in use")
+)
+
 type User_Repository interface {
 	Create(ctx context.Context, user *user.User) error
 	GetByID(ctx context.Context, id int64) (*user.User, error)
@@ -17,6 +34,18 @@ type User_Repository interface {
 	Update(ctx context.Context, user *user.User) error
 	UpdatePassword(ctx context.Context, userID int64, hashedPassword string) error
 	Delete(ctx context.Context, id int64) error
+	CheckPhoneExists(ctx context.Context, phoneNumber string, excludeID int64) (bool, error)
+	CheckEmailExists(ctx context.Context, email string, excludeID int64) (bool, error)
+	GetByIDWithDeleted(ctx context.Context, id int64) (*user.User, error)
+	GetByIDs(ctx context.Context, ids []int64) ([]user.User, error)
+	List(ctx context.Context, filter user.ListFilter) ([]user.User, int64, error)
+	GetStatus(ctx context.Context, id int64) (*user.User, error)
+	SetStatus(ctx context.Context, id int64, status string, reason string, until *time.Time) error
+	SetTokensValidAfter(ctx context.Context, id int64, validAfter time.Time) error
+	Restore(ctx context.Context, id int64) error
+	GetRestorableByPhoneNumber(ctx context.Context, phoneNumber string, deletedAfter time.Time) (*user.User, error)
+	ListPendingPurge(ctx context.Context, deletedBefore time.Time, limit int) ([]user.User, error)
+	Anonymize(ctx context.Context, id int64) error
 }
 type UserRepository struct {
 	db *sqlx.DB
@@ -27,25 +56,36 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 }
 
 func (r *UserRepository) Create(ctx context.Context, user *user.User) error {
+	phoneNumber, err := normalizePhone(user.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	user.PhoneNumber = phoneNumber
+
 	query := `
 		INSERT INTO users (name, phone_number, email, password, photo_object)
 		VALUES ($1, $2, $3, $4, $5)
-		RETURNING id, created_at, updated_at
+		RETURNING id, status, created_at, updated_at
 	`
 
-	err := r.db.QueryRowxContext(ctx, query,
-		user.Name,
-		user.PhoneNumber,
-		user.Email,
-		user.Password,
-		user.PhotoURL,
-	).Scan(&user.ID, &user.CreatedAt, &user.UpdatedAt)
-
-	if err != nil {
-		return fmt.Errorf("create user: %w", err)
-	}
+	return r.inTx(ctx, func(tx *sqlx.Tx) error {
+		err := tx.QueryRowxContext(ctx, query,
+			user.Name,
+			user.PhoneNumber,
+			user.Email,
+			user.Password,
+			user.PhotoObject,
+		).Scan(&user.ID, &user.Status, &user.CreatedAt, &user.UpdatedAt)
+
+		if err != nil {
+			if conflict := uniqueViolation(err); conflict != nil {
+				return conflict
+			}
+			return fmt.Errorf("create user: %w", err)
+		}
 
-	return nil
+		return outboxrepo.Append(ctx, tx, outbox.EventUserCreated, user.ID, outbox.NewUserData(user))
+	})
 }
 
 func (r *UserRepository) GetByID(ctx context.Context, id int64) (*user.User, error) {
@@ -55,7 +95,7 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*user.User, err
 	err := r.db.GetContext(ctx, &user, query, id)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("user not found")
+			return nil, ErrUserNotFound
 		}
 		return nil, fmt.Errorf("failed to get user by id: %w", err)
 	}
@@ -64,10 +104,15 @@ func (r *UserRepository) GetByID(ctx context.Context, id int64) (*user.User, err
 }
 
 func (r *UserRepository) GetByPhoneNumber(ctx context.Context, phoneNumber string) (*user.User, error) {
+	normalized, err := normalizePhone(phoneNumber)
+	if err != nil {
+		return nil, nil
+	}
+
 	var user user.User
 	query := `SELECT * FROM users WHERE phone_number = $1 AND is_deleted = false`
 
-	err := r.db.GetContext(ctx, &user, query, phoneNumber)
+	err = r.db.GetContext(ctx, &user, query, normalized)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, nil
@@ -93,31 +138,80 @@ func (r *UserRepository) GetByEmail(ctx context.Context, email string) (*user.Us
 	return &user, nil
 }
 
-func (r *UserRepository) Update(ctx context.Context, user *user.User) error {
+// Update сохраняет профиль и пишет в outbox user.updated, если изменились данные
+// профиля, и user.photo_changed, если изменилось фото.
+func (r *UserRepository) Update(ctx context.Context, u *user.User) error {
+	phoneNumber, err := normalizePhone(u.PhoneNumber)
+	if err != nil {
+		return err
+	}
+	u.PhoneNumber = phoneNumber
+
 	query := `
 		UPDATE users 
 		SET name = $1, 
 			email = $2, 
 			phone_number = $3,
 			photo_object = $4,
+			display_name = $5,
+			birth_date = $6,
+			locale = $7,
+			timezone = $8,
+			bio = $9,
 			updated_at = NOW()
-		WHERE id = $5 AND is_deleted = false
-		RETURNING updated_at
+		WHERE id = $10 AND is_deleted = false
+		RETURNING *
 	`
 
-	err := r.db.QueryRowContext(ctx, query,
-		user.Name,
-		user.Email,
-		user.PhoneNumber,
-		user.PhotoURL,
-		user.ID,
-	).Scan(&user.UpdatedAt)
+	return r.inTx(ctx, func(tx *sqlx.Tx) error {
+		var previous, updated user.User
+		err := tx.GetContext(ctx, &previous, `SELECT * FROM users WHERE id = $1 AND is_deleted = false FOR UPDATE`, u.ID)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to lock user: %w", err)
+		}
 
-	if err != nil {
-		return fmt.Errorf("failed to update user: %w", err)
-	}
+		err = tx.GetContext(ctx, &updated, query,
+			u.Name,
+			u.Email,
+			u.PhoneNumber,
+			u.PhotoObject,
+			u.DisplayName,
+			u.BirthDate,
+			u.Locale,
+			u.Timezone,
+			u.Bio,
+			u.ID,
+		)
+
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrUserNotFound
+			}
+			if conflict := uniqueViolation(err); conflict != nil {
+				return conflict
+			}
+			return fmt.Errorf("failed to update user: %w", err)
+		}
+		u.UpdatedAt = updated.UpdatedAt
 
-	return nil
+		if profileChanged(&previous, &updated) {
+			if err := outboxrepo.Append(ctx, tx, outbox.EventUserUpdated, u.ID, outbox.NewUserData(&updated)); err != nil {
+				return err
+			}
+		}
+
+		if previous.PhotoObject != updated.PhotoObject {
+			data := outbox.PhotoChangedData{HasPhoto: updated.PhotoObject.Valid && updated.PhotoObject.String != ""}
+			if err := outboxrepo.Append(ctx, tx, outbox.EventUserPhotoChanged, u.ID, data); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
 }
 
 func (r *UserRepository) UpdatePassword(ctx context.Context, userID int64, hashedPassword string) error {
@@ -134,37 +228,44 @@ func (r *UserRepository) UpdatePassword(ctx context.Context, userID int64, hashe
 	}
 
 	if rows == 0 {
-		return fmt.Errorf("user not found")
+		return ErrUserNotFound
 	}
 
 	return nil
 }
 
 func (r *UserRepository) Delete(ctx context.Context, id int64) error {
-	query := `UPDATE users SET is_deleted = true, updated_at = NOW() WHERE id = $1`
+	query := `UPDATE users SET is_deleted = true, deleted_at = NOW(), updated_at = NOW() WHERE id = $1 AND is_deleted = false`
 
-	result, err := r.db.ExecContext(ctx, query, id)
-	if err != nil {
-		return fmt.Errorf("failed to soft delete user: %w", err)
-	}
+	return r.inTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("failed to soft delete user: %w", err)
+		}
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
 
-	if rows == 0 {
-		return fmt.Errorf("user not found")
-	}
+		if rows == 0 {
+			return ErrUserNotFound
+		}
 
-	return nil
+		return outboxrepo.Append(ctx, tx, outbox.EventUserDeleted, id, outbox.DeletedData{})
+	})
 }
 
 func (r *UserRepository) CheckPhoneExists(ctx context.Context, phoneNumber string, excludeID int64) (bool, error) {
+	normalized, err := normalizePhone(phoneNumber)
+	if err != nil {
+		return false, err
+	}
+
 	var exists bool
 	query := `SELECT EXISTS(SELECT 1 FROM users WHERE phone_number = $1 AND id != $2 AND is_deleted = false)`
 
-	err := r.db.GetContext(ctx, &exists, query, phoneNumber, excludeID)
+	err = r.db.GetContext(ctx, &exists, query, normalized, excludeID)
 	if err != nil {
 		return false, fmt.Errorf("failed to check phone existence: %w", err)
 	}
@@ -187,3 +288,442 @@ func (r *UserRepository) CheckEmailExists(ctx context.Context, email string, exc
 
 	return exists, nil
 }
+
+func (r *UserRepository) GetByIDWithDeleted(ctx context.Context, id int64) (*user.User, error) {
+	var user user.User
+	query := `SELECT * FROM users WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &user, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user by id: %w", err)
+	}
+
+	return &user, nil
+}
+
+// GetByIDs возвращает неудаленных пользователей с указанными ID одним запросом.
+// Порядок строк не гарантируется, отсутствующие ID пропускаются.
+func (r *UserRepository) GetByIDs(ctx context.Context, ids []int64) ([]user.User, error) {
+	users := []user.User{}
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	query := `SELECT * FROM users WHERE id = ANY($1) AND is_deleted = false`
+
+	if err := r.db.SelectContext(ctx, &users, query, ids); err != nil {
+		return nil, fmt.Errorf("failed to get users by ids: %w", err)
+	}
+
+	return users, nil
+}
+
+func (r *UserRepository) List(ctx context.Context, filter user.ListFilter) ([]user.User, int64, error) {
+	var conditions []string
+	var args []interface{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if digits := phone.Digits(filter.PhoneNumber); digits != "" {
+		addCondition("phone_number LIKE $%d", "%"+digits+"%")
+	}
+	if filter.Email != "" {
+		addCondition("email ILIKE $%d", "%"+filter.Email+"%")
+	}
+	if filter.Name != "" {
+		addCondition("name ILIKE $%d", "%"+filter.Name+"%")
+	}
+	if filter.Status != "" {
+		addCondition("status = $%d", filter.Status)
+	}
+	if filter.CreatedFrom != nil {
+		addCondition("created_at >= $%d", *filter.CreatedFrom)
+	}
+	if filter.CreatedTo != nil {
+		addCondition("created_at < $%d", *filter.CreatedTo)
+	}
+	if filter.IsDeleted != nil {
+		addCondition("is_deleted = $%d", *filter.IsDeleted)
+	}
+
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	var total int64
+	err := r.db.GetContext(ctx, &total, "SELECT COUNT(*) FROM users "+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+
+	users := []user.User{}
+	query := fmt.Sprintf("SELECT * FROM users %s ORDER BY id DESC LIMIT $%d OFFSET $%d", where, len(args)+1, len(args)+2)
+
+	err = r.db.SelectContext(ctx, &users, query, append(args, filter.Limit, filter.Offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list users: %w", err)
+	}
+
+	return users, total, nil
+}
+
+// GetStatus загружает только поля статуса; используется на каждом запросе в AuthMiddleware.
+func (r *UserRepository) GetStatus(ctx context.Context, id int64) (*user.User, error) {
+	var user user.User
+	query := `
+		SELECT id, status, status_reason, status_until, locale, is_deleted, tokens_valid_after
+		FROM users WHERE id = $1
+	`
+
+	err := r.db.GetContext(ctx, &user, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("failed to get user status: %w", err)
+	}
+
+	return &user, nil
+}
+
+// SetTokensValidAfter отзывает access токены, выданные до validAfter.
+// Отметка только сдвигается вперед, поэтому параллельные отзывы не откатывают друг друга.
+func (r *UserRepository) SetTokensValidAfter(ctx context.Context, id int64, validAfter time.Time) error {
+	query := `
+		UPDATE users
+		SET tokens_valid_after = GREATEST(COALESCE(tokens_valid_after, $1), $1)
+		WHERE id = $2
+	`
+
+	return r.inTx(ctx, func(tx *sqlx.Tx) error {
+		result, err := tx.ExecContext(ctx, query, validAfter, id)
+		if err != nil {
+			return fmt.Errorf("failed to set tokens valid after: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+
+		if rows == 0 {
+			return ErrUserNotFound
+		}
+
+		data := outbox.SessionRevokedData{TokensValidAfter: validAfter.UTC()}
+		return outboxrepo.Append(ctx, tx, outbox.EventSessionRevoked, id, data)
+	})
+}
+
+func (r *UserRepository) SetStatus(ctx context.Context, id int64, status string, reason string, until *time.Time) error {
+	query := `
+		UPDATE users
+		SET status = $1,
+			status_reason = $2,
+			status_until = $3,
+			updated_at = NOW()
+		WHERE id = $4
+		RETURNING *
+	`
+
+	return r.inTx(ctx, func(tx *sqlx.Tx) error {
+		var updated user.User
+		err := tx.GetContext(ctx, &updated, query,
+			status,
+			sql.NullString{String: reason, Valid: reason != ""},
+			until,
+			id,
+		)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrUserNotFound
+			}
+			return fmt.Errorf("failed to update user status: %w", err)
+		}
+
+		return outboxrepo.Append(ctx, tx, outbox.EventUserUpdated, id, outbox.NewUserData(&updated))
+	})
+}
+
+// ListPhotoObjects возвращает все ключи фото, на которые ссылаются пользователи
+func (r *UserRepository) ListPhotoObjects(ctx context.Context) ([]string, error) {
+	objects := []string{}
+	query := `SELECT photo_object FROM users WHERE photo_object IS NOT NULL AND photo_object <> ''`
+
+	err := r.db.SelectContext(ctx, &objects, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list photo objects: %w", err)
+	}
+
+	return objects, nil
+}
+
+// Restore снимает пометку об удалении. Для получателей событий восстановленный
+// аккаунт приходит как user.updated с актуальными данными.
+func (r *UserRepository) Restore(ctx context.Context, id int64) error {
+	query := `
+		UPDATE users SET is_deleted = false, deleted_at = NULL, updated_at = NOW()
+		WHERE id = $1 AND is_deleted = true AND anonymized_at IS NULL
+		RETURNING *
+	`
+
+	return r.inTx(ctx, func(tx *sqlx.Tx) error {
+		var restored user.User
+		err := tx.GetContext(ctx, &restored, query, id)
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrDeletedUserNotFound
+			}
+			return fmt.Errorf("failed to restore user: %w", err)
+		}
+
+		return outboxrepo.Append(ctx, tx, outbox.EventUserUpdated, id, outbox.NewUserData(&restored))
+	})
+}
+
+// GetRestorableByPhoneNumber ищет удаленный, но еще не обезличенный аккаунт,
+// удаленный позже deletedAfter. Возвращает nil, nil, если такого нет.
+func (r *UserRepository) GetRestorableByPhoneNumber(ctx context.Context, phoneNumber string, deletedAfter time.Time) (*user.User, error) {
+	normalized, err := normalizePhone(phoneNumber)
+	if err != nil {
+		return nil, nil
+	}
+
+	var user user.User
+	query := `
+		SELECT * FROM users
+		WHERE phone_number = $1 AND is_deleted = true AND anonymized_at IS NULL AND deleted_at > $2
+	`
+
+	err = r.db.GetContext(ctx, &user, query, normalized, deletedAfter)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get restorable user: %w", err)
+	}
+
+	return &user, nil
+}
+
+// ListPendingPurge возвращает аккаунты, удаленные раньше deletedBefore и еще не обезличенные
+func (r *UserRepository) ListPendingPurge(ctx context.Context, deletedBefore time.Time, limit int) ([]user.User, error) {
+	users := []user.User{}
+	query := `
+		SELECT * FROM users
+		WHERE is_deleted = true AND anonymized_at IS NULL AND deleted_at < $1
+		ORDER BY deleted_at
+		LIMIT $2
+	`
+
+	err := r.db.SelectContext(ctx, &users, query, deletedBefore, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users pending purge: %w", err)
+	}
+
+	return users, nil
+}
+
+// Причины в отчете phone_normalization_conflicts
+const (
+	PhoneConflictInvalid   = "invalid"
+	PhoneConflictCollision = "collision"
+)
+
+// NormalizePhoneNumbers приводит сохраненные номера к E.164 тем же разбором, что и вход
+// (phone.Normalize с регионом phone.defaultregion). Номер, который не разбирается или
+// после нормализации занят другим пользователем, не меняется и попадает в
+// phone_normalization_conflicts. Повторный запуск не дублирует записи отчета.
+func (r *UserRepository) NormalizePhoneNumbers(ctx context.Context) (updated, conflicts int, err error) {
+	var rows []struct {
+		ID          int64  `db:"id"`
+		PhoneNumber string `db:"phone_number"`
+	}
+	// У обезличенных аккаунтов вместо номера заглушка deleted-<id>
+	query := `SELECT id, phone_number FROM users WHERE anonymized_at IS NULL ORDER BY id`
+	if err := r.db.SelectContext(ctx, &rows, query); err != nil {
+		return 0, 0, fmt.Errorf("failed to list phone numbers: %w", err)
+	}
+
+	for _, row := range rows {
+		normalized, err := normalizePhone(row.PhoneNumber)
+		if err != nil {
+			if err := r.reportPhoneConflict(ctx, row.ID, row.PhoneNumber, "", 0, PhoneConflictInvalid); err != nil {
+				return updated, conflicts, err
+			}
+			conflicts++
+			continue
+		}
+		if normalized == row.PhoneNumber {
+			continue
+		}
+
+		_, err = r.db.ExecContext(ctx, `UPDATE users SET phone_number = $1, updated_at = NOW() WHERE id = $2`, normalized, row.ID)
+		if uniqueViolation(err) == ErrPhoneTaken {
+			var otherID int64
+			if err := r.db.GetContext(ctx, &otherID, `SELECT id FROM users WHERE phone_number = $1`, normalized); err != nil {
+				return updated, conflicts, fmt.Errorf("failed to find conflicting user: %w", err)
+			}
+			if err := r.reportPhoneConflict(ctx, row.ID, row.PhoneNumber, normalized, otherID, PhoneConflictCollision); err != nil {
+				return updated, conflicts, err
+			}
+			conflicts++
+			continue
+		}
+		if err != nil {
+			return updated, conflicts, fmt.Errorf("failed to normalize phone of user %d: %w", row.ID, err)
+		}
+		updated++
+	}
+
+	return updated, conflicts, nil
+}
+
+func (r *UserRepository) reportPhoneConflict(ctx context.Context, userID int64, phoneNumber, normalized string, conflictingUserID int64, reason string) error {
+	query := `
+		INSERT INTO phone_normalization_conflicts (user_id, phone_number, normalized, conflicting_user_id, reason)
+		SELECT $1, $2, $3, $4, $5
+		WHERE NOT EXISTS (
+			SELECT 1 FROM phone_normalization_conflicts
+			WHERE user_id = $1 AND phone_number = $2 AND reason = $5
+		)
+	`
+
+	_, err := r.db.ExecContext(ctx, query,
+		userID,
+		phoneNumber,
+		sql.NullString{String: normalized, Valid: normalized != ""},
+		sql.NullInt64{Int64: conflictingUserID, Valid: conflictingUserID != 0},
+		reason,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to report phone conflict: %w", err)
+	}
+
+	return nil
+}
+
+// Anonymize стирает персональные данные удаленного аккаунта. Номер телефона
+// заменяется на заглушку, поэтому номер и email снова доступны для регистрации.
+// Строка остается, чтобы журнал аудита ссылался на существующий id; сами записи
+// журнала обезличиваются.
+func (r *UserRepository) Anonymize(ctx context.Context, id int64) error {
+	return r.inTx(ctx, func(tx *sqlx.Tx) error {
+		query := `
+			UPDATE users
+			SET name = '',
+				phone_number = 'deleted-' || id,
+				email = NULL,
+				password = '',
+				photo_object = NULL,
+				display_name = NULL,
+				birth_date = NULL,
+				locale = NULL,
+				timezone = NULL,
+				bio = NULL,
+				status_reason = NULL,
+				anonymized_at = NOW(),
+				updated_at = NOW()
+			WHERE id = $1 AND is_deleted = true AND anonymized_at IS NULL
+		`
+
+		result, err := tx.ExecContext(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("failed to anonymize user: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rows == 0 {
+			return ErrDeletedUserNotFound
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM user_roles WHERE user_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to remove user roles: %w", err)
+		}
+
+		// В отчете миграции номеров лежат исходные номера телефонов
+		if _, err := tx.ExecContext(ctx, `DELETE FROM phone_normalization_conflicts WHERE user_id = $1`, id); err != nil {
+			return fmt.Errorf("failed to remove phone conflicts: %w", err)
+		}
+
+		// Журнал аудита append-only: redact_audit_events — единственный путь,
+		// которым из него удаляются IP, User-Agent и контактные данные пользователя
+		if _, err := tx.ExecContext(ctx, `SELECT redact_audit_events($1)`, id); err != nil {
+			return fmt.Errorf("failed to redact audit events: %w", err)
+		}
+
+		// Телефон и email остаются в данных прошлых событий outbox и доставок webhook
+		if err := outboxrepo.RedactUser(ctx, tx, id); err != nil {
+			return err
+		}
+		if err := webhookrepo.RedactUser(ctx, tx, id); err != nil {
+			return err
+		}
+
+		return outboxrepo.Append(ctx, tx, outbox.EventUserDeleted, id, outbox.DeletedData{Anonymized: true})
+	})
+}
+
+// inTx выполняет fn в транзакции: изменение пользователя и его события в outbox
+// фиксируются вместе или не фиксируются вовсе.
+func (r *UserRepository) inTx(ctx context.Context, fn func(tx *sqlx.Tx) error) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// profileChanged сообщает, изменились ли данные, которые получают подписчики user.updated
+func profileChanged(previous, updated *user.User) bool {
+	return previous.Name != updated.Name ||
+		previous.Email != updated.Email ||
+		previous.PhoneNumber != updated.PhoneNumber ||
+		previous.DisplayName != updated.DisplayName ||
+		previous.BirthDate.Valid != updated.BirthDate.Valid ||
+		!previous.BirthDate.Time.Equal(updated.BirthDate.Time) ||
+		previous.Locale != updated.Locale ||
+		previous.Timezone != updated.Timezone ||
+		previous.Bio != updated.Bio
+}
+
+// normalizePhone приводит номер к E.164, в базе номера хранятся только так
+func normalizePhone(phoneNumber string) (string, error) {
+	return phone.Normalize(phoneNumber, config.App.Phone.DefaultRegion)
+}
+
+// uniqueViolation переводит нарушение уникальности телефона или email в доменную ошибку
+func uniqueViolation(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return nil
+	}
+
+	switch pgErr.ConstraintName {
+	case "users_phone_number_key":
+		return ErrPhoneTaken
+	case "users_email_key":
+		return ErrEmailTaken
+	}
+	return nil
+}
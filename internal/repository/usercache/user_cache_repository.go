@@ -0,0 +1,126 @@
+package usercacherepo
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"auth_service/internal/model/user"
+
+	"github.com/redis/go-redis/v9"
+)
+
+type User_Cache_Repository interface {
+	GetMany(ctx context.Context, ids []int64) (map[int64]user.User, error)
+	SetMany(ctx context.Context, users []user.User) error
+	Delete(ctx context.Context, ids ...int64) error
+}
+
+// UserCacheRepository хранит в Redis публичные карточки пользователей.
+// Кэшируется ключ фото, а не ссылка: presigned ссылки истекают раньше записи.
+type UserCacheRepository struct {
+	redisClient *redis.Client
+	ttl         time.Duration
+}
+
+func NewUserCacheRepository(redisClient *redis.Client, ttl time.Duration) *UserCacheRepository {
+	return &UserCacheRepository{redisClient: redisClient, ttl: ttl}
+}
+
+// cachedUser — только поля публичного профиля, контактные данные в кэш не попадают
+type cachedUser struct {
+	ID          int64  `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name,omitempty"`
+	PhotoObject string `json:"photo_object,omitempty"`
+}
+
+func userKey(id int64) string {
+	return fmt.Sprintf("user_card:%d", id)
+}
+
+// GetMany читает карточки одним MGET. ID, которых нет в кэше, в результат не попадают.
+func (r *UserCacheRepository) GetMany(ctx context.Context, ids []int64) (map[int64]user.User, error) {
+	users := make(map[int64]user.User, len(ids))
+	if len(ids) == 0 {
+		return users, nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = userKey(id)
+	}
+
+	values, err := r.redisClient.MGet(ctx, keys...).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cached users: %w", err)
+	}
+
+	for i, value := range values {
+		raw, ok := value.(string)
+		if !ok {
+			continue
+		}
+
+		var cached cachedUser
+		if err := json.Unmarshal([]byte(raw), &cached); err != nil {
+			log.Printf("failed to decode cached user %d: %v", ids[i], err)
+			continue
+		}
+
+		users[cached.ID] = user.User{
+			ID:          cached.ID,
+			Name:        cached.Name,
+			DisplayName: sql.NullString{String: cached.DisplayName, Valid: cached.DisplayName != ""},
+			PhotoObject: sql.NullString{String: cached.PhotoObject, Valid: cached.PhotoObject != ""},
+		}
+	}
+
+	return users, nil
+}
+
+func (r *UserCacheRepository) SetMany(ctx context.Context, users []user.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	pipe := r.redisClient.Pipeline()
+	for _, u := range users {
+		payload, err := json.Marshal(cachedUser{
+			ID:          u.ID,
+			Name:        u.Name,
+			DisplayName: u.DisplayName.String,
+			PhotoObject: u.PhotoObject.String,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to encode user %d: %w", u.ID, err)
+		}
+		pipe.Set(ctx, userKey(u.ID), payload, r.ttl)
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cache users: %w", err)
+	}
+
+	return nil
+}
+
+func (r *UserCacheRepository) Delete(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = userKey(id)
+	}
+
+	if err := r.redisClient.Del(ctx, keys...).Err(); err != nil {
+		return fmt.Errorf("failed to delete cached users: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,343 @@
+package webhookrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"auth_service/internal/model/webhook"
+	"auth_service/pkg/apperror"
+
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrEndpointNotFound = apperror.New(apperror.KindNotFound, "webhook_not_found", "webhook not found")
+	ErrDeliveryNotFound = apperror.New(apperror.KindNotFound, "webhook_delivery_not_found", "webhook delivery not found")
+)
+
+type Webhook_Repository interface {
+	CreateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error
+	GetEndpoint(ctx context.Context, id int64) (*webhook.Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]webhook.Endpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error
+	DeleteEndpoint(ctx context.Context, id int64) error
+	EnqueueDeliveries(ctx context.Context, eventID, eventType string, payload []byte) (int64, error)
+	ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error)
+	RecordAttempt(ctx context.Context, delivery *webhook.Delivery, attempt *webhook.Attempt) error
+	GetDelivery(ctx context.Context, endpointID, deliveryID int64) (*webhook.Delivery, error)
+	ListDeliveries(ctx context.Context, endpointID int64, status string, limit, offset int) ([]webhook.Delivery, int64, error)
+	ListAttempts(ctx context.Context, deliveryID int64) ([]webhook.Attempt, error)
+	RequeueDelivery(ctx context.Context, endpointID, deliveryID int64) error
+	DeleteDeliveries(ctx context.Context, before time.Time) (int64, error)
+}
+
+type WebhookRepository struct {
+	db *sqlx.DB
+}
+
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error {
+	query := `
+		INSERT INTO webhook_endpoints (url, secret, event_types, description, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.db.QueryRowxContext(ctx, query,
+		endpoint.URL,
+		endpoint.Secret,
+		endpoint.EventTypes,
+		endpoint.Description,
+		endpoint.IsActive,
+		endpoint.CreatedBy,
+	).Scan(&endpoint.ID, &endpoint.CreatedAt, &endpoint.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("create webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id int64) (*webhook.Endpoint, error) {
+	var endpoint webhook.Endpoint
+	query := `SELECT * FROM webhook_endpoints WHERE id = $1`
+
+	err := r.db.GetContext(ctx, &endpoint, query, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrEndpointNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+
+	return &endpoint, nil
+}
+
+func (r *WebhookRepository) ListEndpoints(ctx context.Context) ([]webhook.Endpoint, error) {
+	endpoints := []webhook.Endpoint{}
+	query := `SELECT * FROM webhook_endpoints ORDER BY id`
+
+	if err := r.db.SelectContext(ctx, &endpoints, query); err != nil {
+		return nil, fmt.Errorf("failed to list webhooks: %w", err)
+	}
+
+	return endpoints, nil
+}
+
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *webhook.Endpoint) error {
+	query := `
+		UPDATE webhook_endpoints
+		SET url = $1,
+			secret = $2,
+			event_types = $3,
+			description = $4,
+			is_active = $5,
+			updated_at = NOW()
+		WHERE id = $6
+		RETURNING updated_at
+	`
+
+	err := r.db.QueryRowContext(ctx, query,
+		endpoint.URL,
+		endpoint.Secret,
+		endpoint.EventTypes,
+		endpoint.Description,
+		endpoint.IsActive,
+		endpoint.ID,
+	).Scan(&endpoint.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrEndpointNotFound
+		}
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteEndpoint удаляет webhook вместе с журналом его доставок
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id int64) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrEndpointNotFound
+	}
+
+	return nil
+}
+
+// RedactUser очищает поле data в доставках событий пользователя, в том числе
+// еще не отправленных: после обезличивания получатель увидит событие без данных.
+// Вызывается в транзакции UserRepository.Anonymize.
+func RedactUser(ctx context.Context, tx sqlx.ExecerContext, userID int64) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET payload = jsonb_set(payload, '{data}', '{}'::jsonb), updated_at = NOW()
+		WHERE (payload->>'user_id')::bigint = $1
+	`
+
+	if _, err := tx.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("failed to redact webhook deliveries: %w", err)
+	}
+
+	return nil
+}
+
+// EnqueueDeliveries создает доставки события на все активные webhook, подписанные
+// на его тип. Повторный вызов с тем же eventID ничего не добавляет.
+func (r *WebhookRepository) EnqueueDeliveries(ctx context.Context, eventID, eventType string, payload []byte) (int64, error) {
+	query := `
+		INSERT INTO webhook_deliveries (endpoint_id, event_id, event_type, payload)
+		SELECT id, $1, $2, $3 FROM webhook_endpoints
+		WHERE is_active AND event_types @> jsonb_build_array($2::text)
+		ON CONFLICT (endpoint_id, event_id) DO NOTHING
+	`
+
+	result, err := r.db.ExecContext(ctx, query, eventID, eventType, payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	return result.RowsAffected()
+}
+
+// ClaimDeliveries забирает до limit готовых к отправке доставок на активные webhook
+// и откладывает их повторную выдачу на lease, как outbox.
+func (r *WebhookRepository) ClaimDeliveries(ctx context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	var deliveries []webhook.Delivery
+	query := `
+		WITH claimed AS (
+			UPDATE webhook_deliveries SET next_attempt_at = $1
+			WHERE id IN (
+				SELECT d.id FROM webhook_deliveries d
+				JOIN webhook_endpoints e ON e.id = d.endpoint_id
+				WHERE d.status = 'pending' AND d.next_attempt_at <= NOW() AND e.is_active
+				ORDER BY d.id
+				LIMIT $2
+				FOR UPDATE OF d SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT c.*, e.url AS endpoint_url, e.secret AS endpoint_secret
+		FROM claimed c
+		JOIN webhook_endpoints e ON e.id = c.endpoint_id
+		ORDER BY c.id
+	`
+
+	err := r.db.SelectContext(ctx, &deliveries, query, time.Now().Add(lease), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// RecordAttempt пишет попытку в журнал и сохраняет новое состояние доставки
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, delivery *webhook.Delivery, attempt *webhook.Attempt) error {
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowxContext(ctx, `
+		INSERT INTO webhook_delivery_attempts (delivery_id, status_code, error, response, duration_ms)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`,
+		delivery.ID,
+		attempt.StatusCode,
+		attempt.Error,
+		attempt.Response,
+		attempt.DurationMS,
+	).Scan(&attempt.ID, &attempt.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $1,
+			attempts = $2,
+			next_attempt_at = $3,
+			last_status_code = $4,
+			last_error = $5,
+			delivered_at = $6,
+			updated_at = NOW()
+		WHERE id = $7
+	`,
+		delivery.Status,
+		delivery.Attempts,
+		delivery.NextAttemptAt,
+		delivery.LastStatusCode,
+		delivery.LastError,
+		delivery.DeliveredAt,
+		delivery.ID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit webhook attempt: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) GetDelivery(ctx context.Context, endpointID, deliveryID int64) (*webhook.Delivery, error) {
+	var delivery webhook.Delivery
+	query := `SELECT * FROM webhook_deliveries WHERE id = $1 AND endpoint_id = $2`
+
+	err := r.db.GetContext(ctx, &delivery, query, deliveryID, endpointID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrDeliveryNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+// ListDeliveries возвращает доставки webhook от новых к старым. Пустой status — все статусы.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, endpointID int64, status string, limit, offset int) ([]webhook.Delivery, int64, error) {
+	where := `WHERE endpoint_id = $1 AND ($2 = '' OR status = $2)`
+
+	var total int64
+	if err := r.db.GetContext(ctx, &total, `SELECT COUNT(*) FROM webhook_deliveries `+where, endpointID, status); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	deliveries := []webhook.Delivery{}
+	query := `SELECT * FROM webhook_deliveries ` + where + ` ORDER BY id DESC LIMIT $3 OFFSET $4`
+	if err := r.db.SelectContext(ctx, &deliveries, query, endpointID, status, limit, offset); err != nil {
+		return nil, 0, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, total, nil
+}
+
+func (r *WebhookRepository) ListAttempts(ctx context.Context, deliveryID int64) ([]webhook.Attempt, error) {
+	attempts := []webhook.Attempt{}
+	query := `SELECT * FROM webhook_delivery_attempts WHERE delivery_id = $1 ORDER BY id`
+
+	if err := r.db.SelectContext(ctx, &attempts, query, deliveryID); err != nil {
+		return nil, fmt.Errorf("failed to list webhook attempts: %w", err)
+	}
+
+	return attempts, nil
+}
+
+// RequeueDelivery возвращает завершенную доставку в очередь с обнуленным счетчиком попыток.
+// Доставка в статусе pending не меняется.
+func (r *WebhookRepository) RequeueDelivery(ctx context.Context, endpointID, deliveryID int64) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND endpoint_id = $2 AND status <> 'pending'
+	`
+
+	result, err := r.db.ExecContext(ctx, query, deliveryID, endpointID)
+	if err != nil {
+		return fmt.Errorf("failed to requeue webhook delivery: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rows == 0 {
+		return ErrDeliveryNotFound
+	}
+
+	return nil
+}
+
+// DeleteDeliveries удаляет завершенные доставки, созданные раньше before
+func (r *WebhookRepository) DeleteDeliveries(ctx context.Context, before time.Time) (int64, error) {
+	query := `DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < $1`
+
+	result, err := r.db.ExecContext(ctx, query, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete webhook deliveries: %w", err)
+	}
+
+	return result.RowsAffected()
+}
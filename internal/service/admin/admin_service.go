@@ -0,0 +1,399 @@
+package adminService
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"auth_service/internal/config"
+	"auth_service/internal/model/audit"
+	"auth_service/internal/model/request"
+	"auth_service/internal/model/role"
+	"auth_service/internal/model/user"
+	rolerepo "auth_service/internal/repository/role"
+	userrepo "auth_service/internal/repository/user"
+	auditService "auth_service/internal/service/audit"
+	sessionService "auth_service/internal/service/session"
+	"auth_service/pkg/apperror"
+	"auth_service/pkg/jwt"
+	"auth_service/pkg/password"
+	"auth_service/pkg/phone"
+	"auth_service/pkg/validation"
+)
+
+const temporaryPasswordLength = 12
+
+var (
+	ErrCannotRevokeOwnSuperadmin   = apperror.New(apperror.KindForbidden, "cannot_revoke_own_superadmin", "cannot revoke own superadmin role")
+	ErrLastSuperadmin              = apperror.New(apperror.KindConflict, "last_superadmin", "cannot revoke the last superadmin")
+	ErrCannotBlockSelf             = apperror.New(apperror.KindForbidden, "cannot_block_self", "cannot block yourself")
+	ErrSuspensionInPast            = apperror.New(apperror.KindInvalid, "suspension_in_past", "suspension end must be in the future")
+	ErrInvalidBlockStatus          = apperror.New(apperror.KindInvalid, "invalid_block_status", "status must be suspended or banned")
+	ErrCannotImpersonateSelf       = apperror.New(apperror.KindForbidden, "cannot_impersonate_self", "cannot impersonate yourself")
+	ErrCannotImpersonateSuperadmin = apperror.New(apperror.KindForbidden, "cannot_impersonate_superadmin", "cannot impersonate a superadmin")
+	ErrCannotManageSuperadmin      = apperror.New(apperror.KindForbidden, "cannot_manage_superadmin", "cannot manage a superadmin")
+	ErrCannotManagePrivilegedUser  = apperror.New(apperror.KindForbidden, "cannot_manage_privileged_user", "cannot manage a user with roles you do not hold")
+)
+
+type Admin_Service interface {
+	ListRoles(ctx context.Context) ([]role.Role, error)
+	GetUserRoles(ctx context.Context, userID int64) ([]string, error)
+	AssignRole(ctx context.Context, actorID, userID int64, roleName string) error
+	RevokeRole(ctx context.Context, actorID, userID int64, roleName string) error
+	ListUsers(ctx context.Context, filter user.ListFilter) ([]user.User, int64, error)
+	GetUser(ctx context.Context, userID int64) (*user.User, error)
+	UpdateUser(ctx context.Context, actorID, userID int64, req request.AdminUpdateUserRequest) (*user.User, error)
+	ResetPassword(ctx context.Context, actorID, userID int64) (string, error)
+	BlockUser(ctx context.Context, actorID, userID int64, req request.BlockUserRequest) error
+	UnblockUser(ctx context.Context, actorID, userID int64) error
+	RestoreUser(ctx context.Context, actorID, userID int64) (*user.User, error)
+	RevokeSessions(ctx context.Context, actorID, userID int64) error
+	QueryAudit(ctx context.Context, filter audit.Filter) ([]audit.Event, int64, error)
+	Impersonate(ctx context.Context, actorID, userID int64) (string, time.Time, error)
+}
+
+type AdminService struct {
+	userRepo       userrepo.User_Repository
+	roleRepo       rolerepo.Role_Repository
+	sessionService sessionService.Session_Service
+	auditService   auditService.Audit_Service
+}
+
+func NewAdminService(
+	userRepo userrepo.User_Repository,
+	roleRepo rolerepo.Role_Repository,
+	sessionService sessionService.Session_Service,
+	auditService auditService.Audit_Service,
+) *AdminService {
+	return &AdminService{
+		userRepo:       userRepo,
+		roleRepo:       roleRepo,
+		sessionService: sessionService,
+		auditService:   auditService,
+	}
+}
+
+func (s *AdminService) ListRoles(ctx context.Context) ([]role.Role, error) {
+	return s.roleRepo.ListRoles(ctx)
+}
+
+func (s *AdminService) GetUserRoles(ctx context.Context, userID int64) ([]string, error) {
+	if _, err := s.userRepo.GetByIDWithDeleted(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	return s.roleRepo.GetUserRoles(ctx, userID)
+}
+
+// AssignRole выдает роль и завершает сессии пользователя: роли зашиты в access токен,
+// поэтому новый набор прав действует после повторного входа.
+func (s *AdminService) AssignRole(ctx context.Context, actorID, userID int64, roleName string) error {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.roleRepo.AssignRole(ctx, userID, roleName); err != nil {
+		return err
+	}
+
+	if err := s.sessionService.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, actorID, userID, audit.ActionAdminRoleAssign, map[string]interface{}{"role": roleName})
+	return nil
+}
+
+// RevokeRole отзывает роль и завершает сессии пользователя, иначе отозванные права
+// оставались бы в уже выданных access токенах до истечения их срока.
+func (s *AdminService) RevokeRole(ctx context.Context, actorID, userID int64, roleName string) error {
+	if roleName == role.RoleSuperAdmin {
+		if actorID == userID {
+			return ErrCannotRevokeOwnSuperadmin
+		}
+
+		// Проверка и удаление в одной транзакции: иначе два одновременных отзыва
+		// могли бы оставить систему без superadmin
+		err := s.roleRepo.RemoveRoleUnlessLast(ctx, userID, roleName)
+		if errors.Is(err, rolerepo.ErrLastRoleHolder) {
+			return ErrLastSuperadmin
+		}
+		if err != nil {
+			return err
+		}
+	} else if err := s.roleRepo.RemoveRole(ctx, userID, roleName); err != nil {
+		return err
+	}
+
+	if err := s.sessionService.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, actorID, userID, audit.ActionAdminRoleRevoke, map[string]interface{}{"role": roleName})
+	return nil
+}
+
+func (s *AdminService) ListUsers(ctx context.Context, filter user.ListFilter) ([]user.User, int64, error) {
+	return s.userRepo.List(ctx, filter)
+}
+
+func (s *AdminService) GetUser(ctx context.Context, userID int64) (*user.User, error) {
+	return s.userRepo.GetByIDWithDeleted(ctx, userID)
+}
+
+// UpdateUser меняет имя, email и телефон пользователя. Телефон и email — данные для входа,
+// поэтому менять их можно только пользователю, прошедшему checkTarget.
+func (s *AdminService) UpdateUser(ctx context.Context, actorID, userID int64, req request.AdminUpdateUserRequest) (*user.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkTarget(ctx, actorID, userID); err != nil {
+		return nil, err
+	}
+
+	// В журнал попадают только имена полей: audit_events не изменяется, и значения
+	// остались бы в нем после обезличивания аккаунта. Номер телефона — в маскированном виде.
+	changed := []string{}
+	metadata := map[string]interface{}{}
+
+	if name := validation.SanitizeInput(req.Name); name != "" && name != user.Name {
+		if err := validation.ValidateUpdateProfileRequest(name, ""); err != nil {
+			return nil, err
+		}
+		changed = append(changed, "name")
+		user.Name = name
+	}
+
+	if req.Email != "" && req.Email != user.Email.String {
+		if !validation.ValidateEmail(req.Email) {
+			return nil, validation.ErrInvalidEmail
+		}
+		exists, err := s.userRepo.CheckEmailExists(ctx, req.Email, userID)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, userrepo.ErrEmailTaken
+		}
+		changed = append(changed, "email")
+		user.Email = sql.NullString{String: req.Email, Valid: true}
+	}
+
+	if strings.TrimSpace(req.PhoneNumber) != "" {
+		phoneNumber, err := phone.Normalize(req.PhoneNumber, config.App.Phone.DefaultRegion)
+		if err != nil {
+			return nil, err
+		}
+		if phoneNumber != user.PhoneNumber {
+			exists, err := s.userRepo.CheckPhoneExists(ctx, phoneNumber, userID)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				return nil, userrepo.ErrPhoneTaken
+			}
+			changed = append(changed, "phone_number")
+			metadata["phone_number"] = map[string]string{"from": phone.Mask(user.PhoneNumber), "to": phone.Mask(phoneNumber)}
+			user.PhoneNumber = phoneNumber
+		}
+	}
+
+	if len(changed) == 0 {
+		return user, nil
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return nil, err
+	}
+
+	metadata["fields"] = changed
+	s.auditService.Record(ctx, actorID, userID, audit.ActionAdminUserUpdate, metadata)
+	return user, nil
+}
+
+// ResetPassword заменяет пароль пользователя временным, завершает все его сессии
+// и возвращает временный пароль для передачи пользователю.
+func (s *AdminService) ResetPassword(ctx context.Context, actorID, userID int64) (string, error) {
+	if err := s.checkTarget(ctx, actorID, userID); err != nil {
+		return "", err
+	}
+
+	temporary, err := password.GenerateRandom(temporaryPasswordLength)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate password: %w", err)
+	}
+
+	hashedPassword, err := password.HashPassword(temporary)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return "", err
+	}
+
+	if err := s.sessionService.RevokeAll(ctx, userID); err != nil {
+		return "", err
+	}
+
+	s.auditService.Record(ctx, actorID, userID, audit.ActionAdminPasswordReset, nil)
+	return temporary, nil
+}
+
+// BlockUser приостанавливает или банит пользователя и завершает все его сессии:
+// выданные до блокировки access токены остаются отозванными и после разблокировки.
+func (s *AdminService) BlockUser(ctx context.Context, actorID, userID int64, req request.BlockUserRequest) error {
+	if actorID == userID {
+		return ErrCannotBlockSelf
+	}
+
+	if err := s.checkTarget(ctx, actorID, userID); err != nil {
+		return err
+	}
+
+	status := req.Status
+	if status == "" {
+		status = user.StatusSuspended
+	}
+
+	until := req.Until
+	switch status {
+	case user.StatusSuspended:
+		if until != nil && !until.After(time.Now()) {
+			return ErrSuspensionInPast
+		}
+	case user.StatusBanned:
+		until = nil
+	default:
+		return ErrInvalidBlockStatus
+	}
+
+	reason := validation.SanitizeInput(req.Reason)
+	if err := s.userRepo.SetStatus(ctx, userID, status, reason, until); err != nil {
+		return err
+	}
+
+	if err := s.sessionService.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+
+	// Причина — свободный текст и может содержать персональные данные. Она хранится
+	// в users.status_reason, которое очищается при обезличивании, а в журнал не пишется.
+	metadata := map[string]interface{}{"status": status, "has_reason": reason != ""}
+	if until != nil {
+		metadata["until"] = until.UTC()
+	}
+	s.auditService.Record(ctx, actorID, userID, audit.ActionAdminUserBlock, metadata)
+	return nil
+}
+
+func (s *AdminService) UnblockUser(ctx context.Context, actorID, userID int64) error {
+	if err := s.userRepo.SetStatus(ctx, userID, user.StatusActive, "", nil); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, actorID, userID, audit.ActionAdminUserUnblock, nil)
+	return nil
+}
+
+func (s *AdminService) RestoreUser(ctx context.Context, actorID, userID int64) (*user.User, error) {
+	if err := s.userRepo.Restore(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, actorID, userID, audit.ActionAdminUserRestore, nil)
+	return s.userRepo.GetByID(ctx, userID)
+}
+
+// RevokeSessions завершает все сессии пользователя, включая выданные access токены
+func (s *AdminService) RevokeSessions(ctx context.Context, actorID, userID int64) error {
+	if _, err := s.userRepo.GetByIDWithDeleted(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.sessionService.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, actorID, userID, audit.ActionAdminSessionsRevoke, nil)
+	return nil
+}
+
+func (s *AdminService) QueryAudit(ctx context.Context, filter audit.Filter) ([]audit.Event, int64, error) {
+	return s.auditService.Query(ctx, filter)
+}
+
+// Impersonate выдает сотруднику короткоживущий access токен пользователя с claim act.
+// По такому токену нельзя обновить сессию, сменить учетные данные или удалить профиль.
+func (s *AdminService) Impersonate(ctx context.Context, actorID, userID int64) (string, time.Time, error) {
+	if actorID == userID {
+		return "", time.Time{}, ErrCannotImpersonateSelf
+	}
+
+	target, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	if err := target.CheckStatus(time.Now()); err != nil {
+		return "", time.Time{}, err
+	}
+
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	for _, name := range roles {
+		if name == role.RoleSuperAdmin {
+			return "", time.Time{}, ErrCannotImpersonateSuperadmin
+		}
+	}
+
+	token, expiresAt, err := jwt.GenerateImpersonationToken(userID, actorID)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	s.auditService.Record(ctx, actorID, userID, audit.ActionAdminImpersonate, map[string]interface{}{
+		"expires_at": expiresAt.UTC(),
+	})
+
+	return token, expiresAt, nil
+}
+
+// checkTarget не дает сотруднику сбросить пароль, сменить данные для входа или заблокировать
+// пользователя с ролью superadmin или с ролью, которой нет у самого сотрудника:
+// иначе admin мог бы завладеть аккаунтом с большими правами.
+func (s *AdminService) checkTarget(ctx context.Context, actorID, userID int64) error {
+	targetRoles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if len(targetRoles) == 0 {
+		return nil
+	}
+
+	actorRoles, err := s.roleRepo.GetUserRoles(ctx, actorID)
+	if err != nil {
+		return err
+	}
+	held := make(map[string]bool, len(actorRoles))
+	for _, name := range actorRoles {
+		held[name] = true
+	}
+
+	for _, name := range targetRoles {
+		if name == role.RoleSuperAdmin {
+			return ErrCannotManageSuperadmin
+		}
+		if !held[name] {
+			return ErrCannotManagePrivilegedUser
+		}
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+package adminService
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"auth_service/internal/model/audit"
+	"auth_service/internal/model/request"
+	"auth_service/internal/model/role"
+	"auth_service/internal/model/user"
+	rolerepo "auth_service/internal/repository/role"
+	userrepo "auth_service/internal/repository/user"
+)
+
+const (
+	adminID      int64 = 1
+	superadminID int64 = 2
+	supportID    int64 = 3
+	plainUserID  int64 = 4
+)
+
+// memUsers — userrepo.User_Repository в памяти. Реализованы только методы,
+// которые вызывает AdminService; остальные паникуют через встроенный nil интерфейс.
+type memUsers struct {
+	userrepo.User_Repository
+
+	users    map[int64]*user.User
+	modified map[int64]bool
+}
+
+func (r *memUsers) GetByID(_ context.Context, id int64) (*user.User, error) {
+	u, ok := r.users[id]
+	if !ok {
+		return nil, userrepo.ErrUserNotFound
+	}
+	copied := *u
+	return &copied, nil
+}
+
+func (r *memUsers) CheckPhoneExists(context.Context, string, int64) (bool, error) {
+	return false, nil
+}
+
+func (r *memUsers) CheckEmailExists(context.Context, string, int64) (bool, error) {
+	return false, nil
+}
+
+func (r *memUsers) Update(_ context.Context, u *user.User) error {
+	r.modified[u.ID] = true
+	return nil
+}
+
+func (r *memUsers) UpdatePassword(_ context.Context, userID int64, _ string) error {
+	r.modified[userID] = true
+	return nil
+}
+
+func (r *memUsers) SetStatus(_ context.Context, id int64, _ string, _ string, _ *time.Time) error {
+	r.modified[id] = true
+	return nil
+}
+
+// memRoles — rolerepo.Role_Repository в памяти, только чтение ролей пользователя
+type memRoles struct {
+	rolerepo.Role_Repository
+
+	roles map[int64][]string
+}
+
+func (r *memRoles) GetUserRoles(_ context.Context, userID int64) ([]string, error) {
+	return r.roles[userID], nil
+}
+
+func (r *memRoles) RemoveRoleUnlessLast(_ context.Context, userID int64, roleName string) error {
+	holders := 0
+	assigned := -1
+	for id, roles := range r.roles {
+		for i, name := range roles {
+			if name == roleName {
+				holders++
+				if id == userID {
+					assigned = i
+				}
+			}
+		}
+	}
+	if assigned < 0 {
+		return rolerepo.ErrRoleNotAssigned
+	}
+	if holders <= 1 {
+		return rolerepo.ErrLastRoleHolder
+	}
+
+	roles := r.roles[userID]
+	r.roles[userID] = append(roles[:assigned:assigned], roles[assigned+1:]...)
+	return nil
+}
+
+type nopSessions struct{}
+
+func (nopSessions) RevokeAll(context.Context, int64) error { return nil }
+
+type nopAudit struct{}
+
+func (nopAudit) Record(context.Context, int64, int64, string, map[string]interface{}) {}
+
+func (nopAudit) ListUserActivity(context.Context, int64, int, int) ([]audit.Event, int64, error) {
+	return nil, 0, nil
+}
+
+func (nopAudit) Query(context.Context, audit.Filter) ([]audit.Event, int64, error) {
+	return nil, 0, nil
+}
+
+func setup() (*AdminService, *memUsers) {
+	users := &memUsers{users: map[int64]*user.User{}, modified: map[int64]bool{}}
+	for _, id := range []int64{adminID, superadminID, supportID, plainUserID} {
+		users.users[id] = &user.User{ID: id, Name: "user", PhoneNumber: "+79161234567", Status: user.StatusActive}
+	}
+
+	roles := &memRoles{roles: map[int64][]string{
+		adminID:      {role.RoleAdmin},
+		superadminID: {role.RoleAdmin, role.RoleSuperAdmin},
+		supportID:    {role.RoleSupport},
+	}}
+
+	return NewAdminService(users, roles, nopSessions{}, nopAudit{}), users
+}
+
+func TestAdminCannotManageHigherPrivilegedUsers(t *testing.T) {
+	actions := map[string]func(s *AdminService, userID int64) error{
+		"update": func(s *AdminService, userID int64) error {
+			_, err := s.UpdateUser(context.Background(), adminID, userID, request.AdminUpdateUserRequest{Email: "new@example.com"})
+			return err
+		},
+		"password reset": func(s *AdminService, userID int64) error {
+			_, err := s.ResetPassword(context.Background(), adminID, userID)
+			return err
+		},
+		"block": func(s *AdminService, userID int64) error {
+			return s.BlockUser(context.Background(), adminID, userID, request.BlockUserRequest{Status: user.StatusBanned})
+		},
+	}
+
+	targets := []struct {
+		name   string
+		userID int64
+		want   error
+	}{
+		{"superadmin", superadminID, ErrCannotManageSuperadmin},
+		{"role the actor does not hold", supportID, ErrCannotManagePrivilegedUser},
+		{"plain user", plainUserID, nil},
+	}
+
+	for action, call := range actions {
+		for _, target := range targets {
+			t.Run(action+"/"+target.name, func(t *testing.T) {
+				s, users := setup()
+
+				err := call(s, target.userID)
+				if !errors.Is(err, target.want) {
+					t.Fatalf("error = %v, want %v", err, target.want)
+				}
+				if modified := users.modified[target.userID]; modified != (target.want == nil) {
+					t.Fatalf("user modified = %v, want %v", modified, target.want == nil)
+				}
+			})
+		}
+	}
+}
+
+func TestRevokeRoleKeepsLastSuperadmin(t *testing.T) {
+	s, _ := setup()
+	roles := s.roleRepo.(*memRoles)
+	roles.roles[adminID] = append(roles.roles[adminID], role.RoleSuperAdmin)
+
+	if err := s.RevokeRole(context.Background(), adminID, superadminID, role.RoleSuperAdmin); err != nil {
+		t.Fatalf("revoke from one of two superadmins: %v", err)
+	}
+
+	err := s.RevokeRole(context.Background(), superadminID, adminID, role.RoleSuperAdmin)
+	if !errors.Is(err, ErrLastSuperadmin) {
+		t.Fatalf("error = %v, want %v", err, ErrLastSuperadmin)
+	}
+}
@@ -0,0 +1,88 @@
+package auditService
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+
+	"auth_service/internal/model/audit"
+	auditrepo "auth_service/internal/repository/audit"
+	"auth_service/pkg/requestinfo"
+)
+
+type Audit_Service interface {
+	Record(ctx context.Context, actorID, subjectID int64, action string, metadata map[string]interface{})
+	ListUserActivity(ctx context.Context, userID int64, limit, offset int) ([]audit.Event, int64, error)
+	Query(ctx context.Context, filter audit.Filter) ([]audit.Event, int64, error)
+}
+
+type AuditService struct {
+	auditRepo *auditrepo.AuditRepository
+}
+
+func NewAuditService(auditRepo *auditrepo.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record пишет событие в журнал, дополняя его IP, User-Agent и ID запроса из контекста.
+// Нулевой actorID или subjectID сохраняется как NULL. Действия, выполненные
+// по токену имперсонации, помечаются impersonated_by. Ошибка записи не должна
+// отменять уже выполненное действие, поэтому только логируется.
+func (s *AuditService) Record(ctx context.Context, actorID, subjectID int64, action string, metadata map[string]interface{}) {
+	info := requestinfo.FromContext(ctx)
+
+	event := &audit.Event{
+		Action:    action,
+		ActorID:   nullableID(actorID),
+		SubjectID: nullableID(subjectID),
+		IP:        nullableString(info.IP),
+		UserAgent: nullableString(info.UserAgent),
+		RequestID: nullableString(info.RequestID),
+	}
+
+	if impersonatorID, ok := requestinfo.ImpersonatorFromContext(ctx); ok {
+		if metadata == nil {
+			metadata = map[string]interface{}{}
+		}
+		metadata["impersonated_by"] = impersonatorID
+	}
+
+	if len(metadata) > 0 {
+		raw, err := json.Marshal(metadata)
+		if err != nil {
+			log.Printf("failed to encode audit metadata for %s: %v", action, err)
+		} else {
+			event.Metadata = raw
+		}
+	}
+
+	if err := s.auditRepo.Create(context.WithoutCancel(ctx), event); err != nil {
+		log.Printf("failed to write audit event %s: %v", action, err)
+	}
+}
+
+func (s *AuditService) ListUserActivity(ctx context.Context, userID int64, limit, offset int) ([]audit.Event, int64, error) {
+	return s.auditRepo.List(ctx, audit.Filter{
+		SubjectID: &userID,
+		Limit:     limit,
+		Offset:    offset,
+	})
+}
+
+func (s *AuditService) Query(ctx context.Context, filter audit.Filter) ([]audit.Event, int64, error) {
+	return s.auditRepo.List(ctx, filter)
+}
+
+func nullableID(id int64) *int64 {
+	if id == 0 {
+		return nil
+	}
+	return &id
+}
+
+func nullableString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -2,56 +2,119 @@ package authService
 
 import (
 	"context"
+	"crypto/sha256"
+	"crypto/subtle"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"log"
 	"strings"
 	"time"
 
 	"auth_service/internal/config"
+	"auth_service/internal/model/audit"
 	"auth_service/internal/model/request"
 	"auth_service/internal/model/user"
 
 	//"auth_service/internal/model"
+	otprepo "auth_service/internal/repository/otp"
+	rolerepo "auth_service/internal/repository/role"
 	tokenrepo "auth_service/internal/repository/token"
 	userrepo "auth_service/internal/repository/user"
+	auditService "auth_service/internal/service/audit"
+	sessionService "auth_service/internal/service/session"
+	"auth_service/pkg/apperror"
+	"auth_service/pkg/i18n"
 	"auth_service/pkg/jwt"
 	"auth_service/pkg/password"
+	"auth_service/pkg/phone"
+	"auth_service/pkg/sms"
+)
+
+var (
+	ErrInvalidCredentials  = apperror.New(apperror.KindUnauthorized, "invalid_credentials", "invalid phone number or password")
+	ErrInvalidRefreshToken = apperror.New(apperror.KindUnauthorized, "invalid_refresh_token", "invalid or expired refresh token")
+	ErrInvalidPassword     = apperror.New(apperror.KindForbidden, "invalid_password", "invalid password")
+	ErrPhoneTaken          = userrepo.ErrPhoneTaken
+	ErrEmailTaken          = userrepo.ErrEmailTaken
+	ErrSamePhone           = apperror.New(apperror.KindInvalid, "same_phone_number", "new phone number matches the current one")
+	ErrCodeRecentlySent    = apperror.New(apperror.KindRateLimited, "otp_recently_sent", "verification code was sent recently, try again later")
+	ErrNoPendingChange     = apperror.New(apperror.KindInvalid, "no_pending_phone_change", "no pending phone change or code expired")
+	ErrInvalidCode         = apperror.New(apperror.KindInvalid, "invalid_otp_code", "invalid verification code")
+	ErrTooManyAttempts     = apperror.New(apperror.KindRateLimited, "otp_attempts_exceeded", "too many attempts, request a new code")
+	ErrInvalidPhone        = phone.ErrInvalid
+	ErrAccountDeleted      = apperror.New(apperror.KindForbidden, "account_deleted", "account is deleted, confirm restore with a code sent to the phone number")
+	ErrNoPendingRestore    = apperror.New(apperror.KindInvalid, "no_pending_account_restore", "no pending account restore or code expired")
 )
 
 type Auth_Service interface {
 	SignUp(ctx context.Context, req request.SignUpRequest) (*user.User, *user.Tokens, error)
 	SignIn(ctx context.Context, req request.LoginRequest) (*user.User, *user.Tokens, error)
-	Logout(ctx context.Context, userID int64, accessToken string) error
+	Logout(ctx context.Context, accessToken string) error
+	LogoutAll(ctx context.Context, userID int64) error
+	RequestPhoneChange(ctx context.Context, userID int64, req request.ChangePhoneRequest) (time.Time, error)
+	ConfirmPhoneChange(ctx context.Context, userID int64, code string) (*user.User, *user.Tokens, error)
+	RequestAccountRestore(ctx context.Context, req request.RestoreAccountRequest) (time.Time, error)
+	ConfirmAccountRestore(ctx context.Context, req request.ConfirmAccountRestoreRequest) (*user.User, *user.Tokens, error)
 }
 
 type Manage_tokens interface {
 	RefreshTokens(ctx context.Context, refreshToken string) (*user.Tokens, error)
-	generateTokens(userID int64) (*user.Tokens, error)
+	generateTokens(ctx context.Context, userID int64) (*user.Tokens, error)
 }
 
 type AuthService struct {
-	userRepo  *userrepo.UserRepository
-	tokenRepo *tokenrepo.TokenRepository
+	userRepo       *userrepo.UserRepository
+	tokenRepo      *tokenrepo.TokenRepository
+	roleRepo       *rolerepo.RoleRepository
+	otpRepo        *otprepo.OTPRepository
+	sessionService *sessionService.SessionService
+	smsSender      sms.Sender
+	auditService   *auditService.AuditService
 }
 
-func NewAuthService(userRepo *userrepo.UserRepository, tokenRepo *tokenrepo.TokenRepository) *AuthService {
+func NewAuthService(
+	userRepo *userrepo.UserRepository,
+	tokenRepo *tokenrepo.TokenRepository,
+	roleRepo *rolerepo.RoleRepository,
+	otpRepo *otprepo.OTPRepository,
+	sessionService *sessionService.SessionService,
+	smsSender sms.Sender,
+	auditService *auditService.AuditService,
+) *AuthService {
 	return &AuthService{
-		userRepo:  userRepo,
-		tokenRepo: tokenRepo,
+		userRepo:       userRepo,
+		tokenRepo:      tokenRepo,
+		roleRepo:       roleRepo,
+		otpRepo:        otpRepo,
+		sessionService: sessionService,
+		smsSender:      smsSender,
+		auditService:   auditService,
 	}
 }
 
 func (s *AuthService) SignUp(ctx context.Context, req request.SignUpRequest) (*user.User, *user.Tokens, error) {
-	existingUser, _ := s.userRepo.GetByPhoneNumber(ctx, req.PhoneNumber)
+	phoneNumber, err := phone.Normalize(req.PhoneNumber, config.App.Phone.DefaultRegion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	existingUser, err := s.userRepo.GetByPhoneNumber(ctx, phoneNumber)
+	if err != nil {
+		return nil, nil, err
+	}
 	if existingUser != nil {
-		return nil, nil, fmt.Errorf("phone number already registered")
+		return nil, nil, ErrPhoneTaken
 	}
 
 	if req.Email != "" {
-		existingUser, _ = s.userRepo.GetByEmail(ctx, req.Email)
+		existingUser, err = s.userRepo.GetByEmail(ctx, req.Email)
+		if err != nil {
+			return nil, nil, err
+		}
 		if existingUser != nil {
-			return nil, nil, fmt.Errorf("email already registered")
+			return nil, nil, ErrEmailTaken
 		}
 	}
 
@@ -62,66 +125,230 @@ func (s *AuthService) SignUp(ctx context.Context, req request.SignUpRequest) (*u
 
 	user := &user.User{
 		Name:        strings.TrimSpace(req.Name),
-		PhoneNumber: req.PhoneNumber,
+		PhoneNumber: phoneNumber,
 		Email:       sql.NullString{String: req.Email, Valid: req.Email != ""},
 		Password:    hashedPassword,
 	}
 
 	err = s.userRepo.Create(ctx, user)
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create user: %w", err)
+		return nil, nil, err
 	}
 
-	tokens, err := s.generateTokens(user.ID)
+	tokens, err := s.generateTokens(ctx, user.ID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
 
+	s.auditService.Record(ctx, user.ID, user.ID, audit.ActionSignUp, nil)
+
 	return user, tokens, nil
 }
 
 func (s *AuthService) SignIn(ctx context.Context, req request.LoginRequest) (*user.User, *user.Tokens, error) {
 	user, err := s.userRepo.GetByPhoneNumber(ctx, req.PhoneNumber)
 	if err != nil {
-		return nil, nil, errors.New("invalid phone number or password")
+		return nil, nil, err
+	}
+
+	if user == nil {
+		// Удаленный аккаунт с верным паролем восстанавливается через /auth/restore
+		deleted, err := s.getRestorable(ctx, req.PhoneNumber)
+		if err != nil {
+			return nil, nil, err
+		}
+		if deleted != nil && password.CheckPassword(req.Password, deleted.Password) {
+			s.auditService.Record(ctx, 0, deleted.ID, audit.ActionSignInFailed, map[string]interface{}{
+				"reason": "account_deleted",
+			})
+			return nil, nil, ErrAccountDeleted
+		}
 	}
 
 	if user == nil {
-		return nil, nil, errors.New("invalid phone number or password")
+		s.auditService.Record(ctx, 0, 0, audit.ActionSignInFailed, map[string]interface{}{
+			"phone_number": phone.Mask(req.PhoneNumber),
+			"reason":       "unknown_phone",
+		})
+		return nil, nil, ErrInvalidCredentials
 	}
 
 	if !password.CheckPassword(req.Password, user.Password) {
-		return nil, nil, errors.New("invalid phone number or password")
+		s.auditService.Record(ctx, 0, user.ID, audit.ActionSignInFailed, map[string]interface{}{
+			"reason": "wrong_password",
+		})
+		return nil, nil, ErrInvalidCredentials
+	}
+
+	if err := s.checkStatus(ctx, user); err != nil {
+		s.auditService.Record(ctx, 0, user.ID, audit.ActionSignInFailed, map[string]interface{}{
+			"reason": "status_" + user.Status,
+		})
+		return nil, nil, err
 	}
 
-	tokens, err := s.generateTokens(user.ID)
+	tokens, err := s.generateTokens(ctx, user.ID)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to generate tokens: %w", err)
 	}
 
+	s.auditService.Record(ctx, user.ID, user.ID, audit.ActionSignIn, nil)
+
 	return user, tokens, nil
 }
 
-func (s *AuthService) Logout(ctx context.Context, userID int64, accessToken string) error {
-	err := s.tokenRepo.DeleteRefreshToken(ctx, userID)
+// getRestorable возвращает аккаунт, удаленный в течение льготного периода, или nil
+func (s *AuthService) getRestorable(ctx context.Context, phoneNumber string) (*user.User, error) {
+	gracePeriod := parseDurationOr(config.App.Deletion.GracePeriod, 30*24*time.Hour)
+	return s.userRepo.GetRestorableByPhoneNumber(ctx, phoneNumber, time.Now().Add(-gracePeriod))
+}
+
+// RequestAccountRestore проверяет пароль удаленного аккаунта и отправляет код
+// подтверждения на его номер. Возвращает срок действия кода.
+func (s *AuthService) RequestAccountRestore(ctx context.Context, req request.RestoreAccountRequest) (time.Time, error) {
+	u, err := s.getRestorable(ctx, req.PhoneNumber)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// Не раскрываем, существует ли удаленный аккаунт с таким номером
+	if u == nil || !password.CheckPassword(req.Password, u.Password) {
+		return time.Time{}, ErrInvalidCredentials
+	}
+
+	if err := u.CheckStatus(time.Now()); err != nil {
+		return time.Time{}, err
+	}
+
+	otpCfg := config.App.OTP
+	allowed, err := s.otpRepo.AcquireRestoreCooldown(ctx, u.ID, parseDurationOr(otpCfg.ResendInterval, time.Minute))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !allowed {
+		return time.Time{}, ErrCodeRecentlySent
+	}
+
+	code, err := password.GenerateNumericCode(otpCfg.Length)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to generate code: %w", err)
+	}
+
+	ttl := parseDurationOr(otpCfg.TTL, 10*time.Minute)
+	if err := s.otpRepo.StoreAccountRestore(ctx, u.ID, hashCode(u.ID, u.PhoneNumber, code), ttl); err != nil {
+		return time.Time{}, err
+	}
+
+	message := i18n.T(i18n.FromContext(ctx), "sms.account_restore_code", code)
+	if err := s.smsSender.Send(ctx, u.PhoneNumber, message); err != nil {
+		s.otpRepo.DeleteAccountRestore(ctx, u.ID)
+		return time.Time{}, fmt.Errorf("failed to send code: %w", err)
+	}
+
+	return time.Now().Add(ttl), nil
+}
+
+// ConfirmAccountRestore проверяет код и снимает пометку об удалении.
+// Аккаунт получает новую пару токенов.
+func (s *AuthService) ConfirmAccountRestore(ctx context.Context, req request.ConfirmAccountRestoreRequest) (*user.User, *user.Tokens, error) {
+	u, err := s.getRestorable(ctx, req.PhoneNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	if u == nil {
+		return nil, nil, ErrNoPendingRestore
+	}
+
+	pending, err := s.otpRepo.GetAccountRestore(ctx, u.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending == nil {
+		return nil, nil, ErrNoPendingRestore
+	}
+
+	attempts, err := s.otpRepo.IncrementAccountRestoreAttempts(ctx, u.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attempts > config.App.OTP.MaxAttempts {
+		s.otpRepo.DeleteAccountRestore(ctx, u.ID)
+		return nil, nil, ErrTooManyAttempts
+	}
+
+	expected := hashCode(u.ID, u.PhoneNumber, strings.TrimSpace(req.Code))
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(pending.CodeHash)) != 1 {
+		return nil, nil, ErrInvalidCode
+	}
+
+	if err := s.userRepo.Restore(ctx, u.ID); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.otpRepo.DeleteAccountRestore(ctx, u.ID); err != nil {
+		log.Printf("failed to delete account restore for user %d: %v", u.ID, err)
+	}
+
+	tokens, err := s.generateTokens(ctx, u.ID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate tokens: %w", err)
+	}
+
+	s.auditService.Record(ctx, u.ID, u.ID, audit.ActionAccountRestore, map[string]interface{}{
+		"deleted_at": u.DeletedAt.Time.UTC(),
+	})
+
+	u.IsDeleted = false
+	u.DeletedAt = sql.NullTime{}
+	u.Password = ""
+	return u, tokens, nil
+}
+
+// Logout отзывает access токен и refresh токен его владельца. Для токена имперсонации
+// отзывается только он сам: сессия пользователя остается нетронутой.
+func (s *AuthService) Logout(ctx context.Context, accessToken string) error {
+	claims, err := jwt.ValidateAccessToken(accessToken)
 	if err != nil {
-		return fmt.Errorf("failed to delete refresh token: %w", err)
+		return apperror.ErrUnauthorized.Wrap(err)
+	}
+	userID := claims.UserID
+
+	if !claims.IsImpersonation() {
+		err = s.tokenRepo.DeleteRefreshToken(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("failed to delete refresh token: %w", err)
+		}
 	}
 
 	ttl := parseDuration(config.App.JWT.AccessTTL)
+	if claims.ExpiresAt != nil {
+		ttl = time.Until(claims.ExpiresAt.Time)
+	}
 	err = s.tokenRepo.StoreBlacklistedToken(ctx, accessToken, ttl)
 
 	if err != nil {
 		return fmt.Errorf("failed to blacklist token: %w", err)
 	}
 
+	s.auditService.Record(ctx, userID, userID, audit.ActionLogout, nil)
+
+	return nil
+}
+
+// LogoutAll завершает все сессии пользователя, включая текущую
+func (s *AuthService) LogoutAll(ctx context.Context, userID int64) error {
+	if err := s.sessionService.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, userID, userID, audit.ActionLogoutAll, nil)
+
 	return nil
 }
 
 func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string) (*user.Tokens, error) {
 	claims, err := jwt.ValidateRefreshToken(refreshToken)
 	if err != nil {
-		return nil, errors.New("invalid or expired refresh token")
+		return nil, ErrInvalidRefreshToken.Wrap(err)
 	}
 
 	blacklisted, err := s.tokenRepo.IsTokenBlacklisted(ctx, refreshToken)
@@ -129,28 +356,196 @@ func (s *AuthService) RefreshTokens(ctx context.Context, refreshToken string) (*
 		return nil, fmt.Errorf("failed to check token blacklist: %w", err)
 	}
 	if blacklisted {
-		return nil, errors.New("token is blacklisted")
+		return nil, ErrInvalidRefreshToken
 	}
 
 	storedToken, err := s.tokenRepo.GetRefreshToken(ctx, claims.UserID)
+	if errors.Is(err, tokenrepo.ErrRefreshTokenNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
 	if err != nil {
-		return nil, errors.New("refresh token not found")
+		return nil, err
 	}
 
 	if storedToken != refreshToken {
-		return nil, errors.New("refresh token mismatch")
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.GetByID(ctx, claims.UserID)
+	if errors.Is(err, userrepo.ErrUserNotFound) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkStatus(ctx, user); err != nil {
+		return nil, err
 	}
 
-	tokens, err := s.generateTokens(claims.UserID)
+	tokens, err := s.generateTokens(ctx, claims.UserID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate new tokens: %w", err)
 	}
 
+	s.auditService.Record(ctx, claims.UserID, claims.UserID, audit.ActionTokensRefresh, nil)
+
 	return tokens, nil
 }
 
-func (s *AuthService) generateTokens(userID int64) (*user.Tokens, error) {
-	accessToken, err := jwt.GenerateAccessToken(userID)
+// RequestPhoneChange проверяет текущий пароль и свободность нового номера,
+// затем отправляет на новый номер код подтверждения. Возвращает срок действия кода.
+func (s *AuthService) RequestPhoneChange(ctx context.Context, userID int64, req request.ChangePhoneRequest) (time.Time, error) {
+	newPhone, err := phone.Normalize(req.NewPhoneNumber, config.App.Phone.DefaultRegion)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	if !password.CheckPassword(req.Password, u.Password) {
+		return time.Time{}, ErrInvalidPassword
+	}
+
+	if newPhone == u.PhoneNumber {
+		return time.Time{}, ErrSamePhone
+	}
+
+	exists, err := s.userRepo.CheckPhoneExists(ctx, newPhone, userID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if exists {
+		return time.Time{}, ErrPhoneTaken
+	}
+
+	otpCfg := config.App.OTP
+	allowed, err := s.otpRepo.AcquireCooldown(ctx, userID, parseDurationOr(otpCfg.ResendInterval, time.Minute))
+	if err != nil {
+		return time.Time{}, err
+	}
+	if !allowed {
+		return time.Time{}, ErrCodeRecentlySent
+	}
+
+	code, err := password.GenerateNumericCode(otpCfg.Length)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to generate code: %w", err)
+	}
+
+	ttl := parseDurationOr(otpCfg.TTL, 10*time.Minute)
+	if err := s.otpRepo.StorePhoneChange(ctx, userID, newPhone, hashCode(userID, newPhone, code), ttl); err != nil {
+		return time.Time{}, err
+	}
+
+	message := i18n.T(i18n.FromContext(ctx), "sms.phone_change_code", code)
+	if err := s.smsSender.Send(ctx, newPhone, message); err != nil {
+		s.otpRepo.DeletePhoneChange(ctx, userID)
+		return time.Time{}, fmt.Errorf("failed to send code: %w", err)
+	}
+
+	s.auditService.Record(ctx, userID, userID, audit.ActionPhoneChangeRequest, map[string]interface{}{
+		"new_phone_number": phone.Mask(newPhone),
+	})
+
+	return time.Now().Add(ttl), nil
+}
+
+// ConfirmPhoneChange проверяет код и меняет номер. Refresh токен других сессий
+// отзывается, текущая сессия получает новую пару токенов.
+func (s *AuthService) ConfirmPhoneChange(ctx context.Context, userID int64, code string) (*user.User, *user.Tokens, error) {
+	pending, err := s.otpRepo.GetPhoneChange(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if pending == nil {
+		return nil, nil, ErrNoPendingChange
+	}
+
+	attempts, err := s.otpRepo.IncrementPhoneChangeAttempts(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if attempts > config.App.OTP.MaxAttempts {
+		s.otpRepo.DeletePhoneChange(ctx, userID)
+		return nil, nil, ErrTooManyAttempts
+	}
+
+	expected := hashCode(userID, pending.NewPhone, strings.TrimSpace(code))
+	if subtle.ConstantTimeCompare([]byte(expected), []byte(pending.CodeHash)) != 1 {
+		return nil, nil, ErrInvalidCode
+	}
+
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Номер мог занять кто-то другой, пока код был в пути
+	exists, err := s.userRepo.CheckPhoneExists(ctx, pending.NewPhone, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if exists {
+		s.otpRepo.DeletePhoneChange(ctx, userID)
+		return nil, nil, ErrPhoneTaken
+	}
+
+	oldPhone := u.PhoneNumber
+	u.PhoneNumber = pending.NewPhone
+	if err := s.userRepo.Update(ctx, u); err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.otpRepo.DeletePhoneChange(ctx, userID); err != nil {
+		log.Printf("failed to delete phone change for user %d: %v", userID, err)
+	}
+
+	// Номер — логин: все прежние сессии завершаются, клиент получает новые токены
+	if err := s.sessionService.RevokeAll(ctx, userID); err != nil {
+		return nil, nil, err
+	}
+
+	tokens, err := s.generateTokens(ctx, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.auditService.Record(ctx, userID, userID, audit.ActionPhoneChange, map[string]interface{}{
+		"from": phone.Mask(oldPhone),
+		"to":   phone.Mask(u.PhoneNumber),
+	})
+
+	u.Password = ""
+	return u, tokens, nil
+}
+
+// hashCode — в Redis хранится только хэш кода, привязанный к пользователю и номеру
+func hashCode(userID int64, phone, code string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%d:%s:%s", userID, phone, code)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *AuthService) generateTokens(ctx context.Context, userID int64) (*user.Tokens, error) {
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user roles: %w", err)
+	}
+
+	// Отметка отзыва округлена вверх до секунды, поэтому после RevokeAll в ту же секунду
+	// (смена телефона, вход сразу после выхода со всех устройств) iat нового токена
+	// берется не раньше нее, иначе токен был бы отклонен как отозванный
+	issuedAt := time.Now()
+	validAfter, ok, err := s.tokenRepo.GetTokensValidAfter(ctx, userID)
+	if err != nil {
+		log.Printf("failed to get tokens valid after for user %d: %v", userID, err)
+	} else if ok && validAfter.After(issuedAt) {
+		issuedAt = validAfter
+	}
+
+	accessToken, err := jwt.GenerateAccessToken(userID, roles, issuedAt)
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate access token: %w", err)
 	}
@@ -161,7 +556,6 @@ func (s *AuthService) generateTokens(userID int64) (*user.Tokens, error) {
 
 	}
 
-	ctx := context.Background()
 	err = s.tokenRepo.StoreRefreshToken(ctx, userID, refreshToken)
 	if err != nil {
 		return nil, fmt.Errorf("failed to store refresh token: %w", err)
@@ -173,6 +567,33 @@ func (s *AuthService) generateTokens(userID int64) (*user.Tokens, error) {
 	}, nil
 }
 
+// checkStatus возвращает *user.StatusError для неактивного аккаунта.
+// Истекшая приостановка снимается в базе при первой проверке.
+func (s *AuthService) checkStatus(ctx context.Context, u *user.User) error {
+	if err := u.CheckStatus(time.Now()); err != nil {
+		return err
+	}
+
+	if u.Status != user.StatusActive {
+		if err := s.userRepo.SetStatus(ctx, u.ID, user.StatusActive, "", nil); err != nil {
+			log.Printf("failed to lift expired suspension for user %d: %v", u.ID, err)
+		}
+		u.Status = user.StatusActive
+		u.StatusReason = sql.NullString{}
+		u.StatusUntil = sql.NullTime{}
+	}
+
+	return nil
+}
+
+func parseDurationOr(durationStr string, fallback time.Duration) time.Duration {
+	dur, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return fallback
+	}
+	return dur
+}
+
 func parseDuration(durationStr string) time.Duration {
 	dur, err := time.ParseDuration(durationStr)
 	if err != nil {
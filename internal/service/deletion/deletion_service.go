@@ -0,0 +1,158 @@
+package deletionService
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"auth_service/internal/model/audit"
+	exportrepo "auth_service/internal/repository/export"
+	otprepo "auth_service/internal/repository/otp"
+	tokenrepo "auth_service/internal/repository/token"
+	userrepo "auth_service/internal/repository/user"
+	auditService "auth_service/internal/service/audit"
+	"auth_service/internal/storage/blob"
+)
+
+const purgeBatchSize = 100
+
+// userPrefixes — все объекты пользователя: фото, миниатюры, прямые загрузки и выгрузки
+var userPrefixes = []string{"users/%d/", "exports/%d/"}
+
+type Account_Purge_Service interface {
+	Run(ctx context.Context, interval time.Duration)
+	Purge(ctx context.Context) (int, error)
+}
+
+// AccountPurgeService обезличивает аккаунты, удаленные больше gracePeriod назад:
+// стирает персональные данные в базе, удаляет файлы из хранилища и состояние в Redis.
+type AccountPurgeService struct {
+	userRepo     *userrepo.UserRepository
+	tokenRepo    *tokenrepo.TokenRepository
+	otpRepo      *otprepo.OTPRepository
+	exportRepo   *exportrepo.ExportRepository
+	blobs        blob.BlobStore
+	auditService *auditService.AuditService
+	gracePeriod  time.Duration
+}
+
+func NewAccountPurgeService(
+	userRepo *userrepo.UserRepository,
+	tokenRepo *tokenrepo.TokenRepository,
+	otpRepo *otprepo.OTPRepository,
+	exportRepo *exportrepo.ExportRepository,
+	blobs blob.BlobStore,
+	auditService *auditService.AuditService,
+	gracePeriod time.Duration,
+) *AccountPurgeService {
+	return &AccountPurgeService{
+		userRepo:     userRepo,
+		tokenRepo:    tokenRepo,
+		otpRepo:      otpRepo,
+		exportRepo:   exportRepo,
+		blobs:        blobs,
+		auditService: auditService,
+		gracePeriod:  gracePeriod,
+	}
+}
+
+// Run запускает Purge с заданным интервалом до отмены ctx
+func (s *AccountPurgeService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			purged, err := s.Purge(ctx)
+			if err != nil {
+				log.Printf("account purge failed: %v", err)
+				continue
+			}
+			if purged > 0 {
+				log.Printf("account purge anonymized %d accounts", purged)
+			}
+		}
+	}
+}
+
+// Purge обрабатывает все аккаунты с истекшим льготным периодом пачками.
+// Аккаунт, который не удалось очистить, остается в выборке до следующего запуска.
+func (s *AccountPurgeService) Purge(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.gracePeriod)
+	purged := 0
+
+	for {
+		users, err := s.userRepo.ListPendingPurge(ctx, cutoff, purgeBatchSize)
+		if err != nil {
+			return purged, err
+		}
+
+		failed := 0
+		for _, u := range users {
+			if err := s.purgeUser(ctx, u.ID); err != nil {
+				log.Printf("account purge: user %d: %v", u.ID, err)
+				failed++
+				continue
+			}
+			purged++
+		}
+
+		// Если вся пачка упала, следующая выборка вернет те же строки
+		if len(users) < purgeBatchSize || failed == len(users) {
+			return purged, nil
+		}
+	}
+}
+
+// purgeUser сначала удаляет файлы и состояние в Redis, а затем обезличивает строку:
+// если процесс прервется, аккаунт останется в выборке и будет дочищен позже.
+// Задачи выгрузки удаляются до файлов: воркер, собирающий архив, увидит,
+// что задачи нет, и не сохранит его после очистки exports/<user_id>/.
+func (s *AccountPurgeService) purgeUser(ctx context.Context, userID int64) error {
+	if err := s.exportRepo.DeleteAll(ctx, userID); err != nil {
+		return err
+	}
+
+	for _, pattern := range userPrefixes {
+		if err := s.removePrefix(ctx, fmt.Sprintf(pattern, userID)); err != nil {
+			return err
+		}
+	}
+
+	if err := s.tokenRepo.DeleteRefreshToken(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.otpRepo.DeleteAll(ctx, userID); err != nil {
+		return err
+	}
+
+	if err := s.userRepo.Anonymize(ctx, userID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, 0, userID, audit.ActionProfilePurge, nil)
+	return nil
+}
+
+func (s *AccountPurgeService) removePrefix(ctx context.Context, prefix string) error {
+	keys := []string{}
+	err := s.blobs.List(ctx, prefix, func(info blob.ObjectInfo) error {
+		keys = append(keys, info.Key)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list %s: %w", prefix, err)
+	}
+
+	for _, key := range keys {
+		if err := s.blobs.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", key, err)
+		}
+	}
+
+	return nil
+}
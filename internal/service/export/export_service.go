@@ -0,0 +1,448 @@
+package exportService
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"auth_service/internal/config"
+	"auth_service/internal/model/audit"
+	"auth_service/internal/model/export"
+	"auth_service/internal/model/responce"
+	exportrepo "auth_service/internal/repository/export"
+	rolerepo "auth_service/internal/repository/role"
+	userrepo "auth_service/internal/repository/user"
+	auditService "auth_service/internal/service/audit"
+	"auth_service/internal/storage/blob"
+	"auth_service/pkg/apperror"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// exportPrefix — архивы лежат под exports/<user_id>/, сборщик фото их не трогает
+	exportPrefix  = "exports/"
+	auditPageSize = 500
+	sweepInterval = time.Hour
+)
+
+var (
+	ErrExportNotFound  = apperror.New(apperror.KindNotFound, "export_not_found", "export not found")
+	ErrExportQueueFull = apperror.New(apperror.KindRateLimited, "export_queue_full", "too many exports in progress, try again later")
+
+	errExportCancelled = errors.New("export cancelled: account deleted")
+)
+
+// sessionActions — события, из которых складывается история сессий
+var sessionActions = map[string]bool{
+	audit.ActionSignUp:        true,
+	audit.ActionSignIn:        true,
+	audit.ActionSignInFailed:  true,
+	audit.ActionTokensRefresh: true,
+	audit.ActionLogout:        true,
+}
+
+type Export_Service interface {
+	RequestExport(ctx context.Context, userID int64) (*export.JobResponse, error)
+	GetExport(ctx context.Context, userID int64, jobID string) (*export.JobResponse, error)
+	Run(ctx context.Context)
+}
+
+// ExportService собирает архив со всеми данными пользователя в фоне.
+// Задачи хранятся в Redis, очередь — в памяти процесса: после перезапуска
+// незавершенная задача истечет вместе с записью, и пользователь запросит новую.
+type ExportService struct {
+	userRepo     *userrepo.UserRepository
+	roleRepo     *rolerepo.RoleRepository
+	exportRepo   *exportrepo.ExportRepository
+	blobs        blob.BlobStore
+	auditService *auditService.AuditService
+	queue        chan string
+	retention    time.Duration
+	urlTTL       time.Duration
+}
+
+func NewExportService(
+	userRepo *userrepo.UserRepository,
+	roleRepo *rolerepo.RoleRepository,
+	exportRepo *exportrepo.ExportRepository,
+	blobs blob.BlobStore,
+	auditService *auditService.AuditService,
+) *ExportService {
+	cfg := config.App.Export
+
+	queueSize := cfg.QueueSize
+	if queueSize < 1 {
+		queueSize = 100
+	}
+
+	return &ExportService{
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		exportRepo:   exportRepo,
+		blobs:        blobs,
+		auditService: auditService,
+		queue:        make(chan string, queueSize),
+		retention:    parseDuration(cfg.Retention, 24*time.Hour),
+		urlTTL:       parseDuration(cfg.URLTTL, 15*time.Minute),
+	}
+}
+
+// RequestExport ставит выгрузку в очередь. Если предыдущая выгрузка еще
+// не завершена, возвращается она.
+func (s *ExportService) RequestExport(ctx context.Context, userID int64) (*export.JobResponse, error) {
+	latest, err := s.exportRepo.GetLatest(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.IsActive() {
+		resp := latest.ToResponse()
+		return &resp, nil
+	}
+
+	job := &export.Job{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Status:    export.StatusPending,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.exportRepo.Create(ctx, job, s.retention); err != nil {
+		return nil, err
+	}
+
+	select {
+	case s.queue <- job.ID:
+	default:
+		if err := s.exportRepo.Delete(ctx, job); err != nil {
+			log.Printf("failed to drop export job %s: %v", job.ID, err)
+		}
+		return nil, ErrExportQueueFull
+	}
+
+	s.auditService.Record(ctx, userID, userID, audit.ActionDataExport, map[string]interface{}{
+		"job_id": job.ID,
+	})
+
+	resp := job.ToResponse()
+	return &resp, nil
+}
+
+// GetExport возвращает состояние выгрузки. Для готового архива выдается
+// временная ссылка на скачивание.
+func (s *ExportService) GetExport(ctx context.Context, userID int64, jobID string) (*export.JobResponse, error) {
+	job, err := s.exportRepo.Get(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil || job.UserID != userID {
+		return nil, ErrExportNotFound
+	}
+
+	resp := job.ToResponse()
+	if job.Status == export.StatusReady {
+		downloadURL, err := s.blobs.PresignGet(ctx, job.ObjectKey, s.urlTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to presign export: %w", err)
+		}
+		expiresAt := time.Now().Add(s.urlTTL).UTC()
+		resp.DownloadURL = downloadURL
+		resp.DownloadExpiresAt = &expiresAt
+	}
+
+	return &resp, nil
+}
+
+// Run запускает обработчики очереди и удаление старых архивов до отмены ctx
+func (s *ExportService) Run(ctx context.Context) {
+	workers := config.App.Export.Workers
+	if workers < 1 {
+		workers = 1
+	}
+
+	for i := 0; i < workers; i++ {
+		go s.work(ctx)
+	}
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := s.Sweep(ctx)
+			if err != nil {
+				log.Printf("export sweep failed: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("export sweep removed %d archives", removed)
+			}
+		}
+	}
+}
+
+// Sweep удаляет архивы старше срока хранения задач
+func (s *ExportService) Sweep(ctx context.Context) (int, error) {
+	cutoff := time.Now().Add(-s.retention)
+	removed := 0
+
+	err := s.blobs.List(ctx, exportPrefix, func(info blob.ObjectInfo) error {
+		if strings.HasSuffix(info.Key, "/") || info.LastModified.After(cutoff) {
+			return nil
+		}
+
+		if err := s.blobs.Delete(ctx, info.Key); err != nil {
+			log.Printf("export sweep: failed to remove %s: %v", info.Key, err)
+			return nil
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to list exports: %w", err)
+	}
+
+	return removed, nil
+}
+
+func (s *ExportService) work(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case jobID := <-s.queue:
+			if err := s.process(ctx, jobID); err != nil {
+				log.Printf("export job %s failed: %v", jobID, err)
+			}
+		}
+	}
+}
+
+func (s *ExportService) process(ctx context.Context, jobID string) error {
+	job, err := s.exportRepo.Get(ctx, jobID)
+	if err != nil {
+		return err
+	}
+	if job == nil {
+		return nil
+	}
+
+	job.Status = export.StatusProcessing
+	if err := s.exportRepo.Update(ctx, job); err != nil {
+		return err
+	}
+
+	archive, err := s.buildArchive(ctx, job.UserID)
+	if err == nil {
+		job.ObjectKey = fmt.Sprintf("%s%d/%s.zip", exportPrefix, job.UserID, job.ID)
+		err = s.store(ctx, job, archive)
+	}
+
+	completedAt := time.Now().UTC()
+	job.CompletedAt = &completedAt
+	job.Status = export.StatusReady
+	if err != nil {
+		job.Status = export.StatusFailed
+		job.ObjectKey = ""
+		job.Error = err.Error()
+	}
+
+	if updateErr := s.exportRepo.Update(ctx, job); updateErr != nil {
+		return updateErr
+	}
+	return err
+}
+
+// store сохраняет архив, только если аккаунт не удален и задача еще существует.
+// Удаление аккаунта стирает задачи и файлы exports/<user_id>/; повторная проверка
+// после загрузки убирает архив, если это случилось, пока он загружался.
+func (s *ExportService) store(ctx context.Context, job *export.Job, archive []byte) error {
+	if err := s.checkNotCancelled(ctx, job); err != nil {
+		return err
+	}
+
+	if err := s.blobs.Put(ctx, job.ObjectKey, bytes.NewReader(archive), int64(len(archive)), "application/zip"); err != nil {
+		return err
+	}
+
+	if err := s.checkNotCancelled(ctx, job); err != nil {
+		if deleteErr := s.blobs.Delete(ctx, job.ObjectKey); deleteErr != nil {
+			log.Printf("failed to remove cancelled export %s: %v", job.ObjectKey, deleteErr)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkNotCancelled возвращает errExportCancelled, если аккаунт удален
+// или задачу удалил AccountPurgeService
+func (s *ExportService) checkNotCancelled(ctx context.Context, job *export.Job) error {
+	account, err := s.userRepo.GetStatus(ctx, job.UserID)
+	if errors.Is(err, userrepo.ErrUserNotFound) {
+		return errExportCancelled
+	}
+	if err != nil {
+		return err
+	}
+	if account.IsDeleted {
+		return errExportCancelled
+	}
+
+	current, err := s.exportRepo.Get(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil {
+		return errExportCancelled
+	}
+
+	return nil
+}
+
+// profileExport — содержимое profile.json
+type profileExport struct {
+	Profile    responce.UserResponse `json:"profile"`
+	Status     string                `json:"status"`
+	Roles      []string              `json:"roles"`
+	ExportedAt time.Time             `json:"exported_at"`
+}
+
+// buildArchive собирает ZIP: profile.json, sessions.json, audit_events.json и фото профиля
+func (s *ExportService) buildArchive(ctx context.Context, userID int64) ([]byte, error) {
+	u, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	events, err := s.listActivity(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]audit.Activity, 0)
+	for _, event := range events {
+		if sessionActions[event.Action] {
+			sessions = append(sessions, event)
+		}
+	}
+
+	profile := profileExport{
+		Profile:    u.ToResponse(),
+		Status:     u.Status,
+		Roles:      roles,
+		ExportedAt: time.Now().UTC(),
+	}
+	// Временные ссылки в архиве бесполезны, фото лежит рядом
+	profile.Profile.PhotoURL = ""
+	profile.Profile.PhotoThumbnails = nil
+
+	var photoName string
+	if u.PhotoObject.Valid && u.PhotoObject.String != "" {
+		photoName = "photo/" + path.Base(u.PhotoObject.String)
+		profile.Profile.PhotoURL = photoName
+	}
+
+	var buf bytes.Buffer
+	archive := zip.NewWriter(&buf)
+
+	files := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", profile},
+		{"sessions.json", sessions},
+		{"audit_events.json", events},
+	}
+	for _, file := range files {
+		if err := writeJSON(archive, file.name, file.data); err != nil {
+			return nil, err
+		}
+	}
+
+	if photoName != "" {
+		if err := s.copyObject(ctx, archive, photoName, u.PhotoObject.String); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish archive: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// listActivity читает весь журнал событий пользователя постранично
+func (s *ExportService) listActivity(ctx context.Context, userID int64) ([]audit.Activity, error) {
+	activity := make([]audit.Activity, 0)
+
+	for offset := 0; ; offset += auditPageSize {
+		events, total, err := s.auditService.ListUserActivity(ctx, userID, auditPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for i := range events {
+			activity = append(activity, events[i].ToActivity())
+		}
+		if len(events) < auditPageSize || int64(offset+len(events)) >= total {
+			return activity, nil
+		}
+	}
+}
+
+func (s *ExportService) copyObject(ctx context.Context, archive *zip.Writer, name, key string) error {
+	body, _, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to read photo: %w", err)
+	}
+	defer body.Close()
+
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	if _, err := io.Copy(w, body); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func writeJSON(archive *zip.Writer, name string, data interface{}) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+
+	return nil
+}
+
+func parseDuration(durationStr string, fallback time.Duration) time.Duration {
+	dur, err := time.ParseDuration(durationStr)
+	if err != nil || dur <= 0 {
+		return fallback
+	}
+	return dur
+}
@@ -0,0 +1,161 @@
+package outboxService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"auth_service/internal/model/outbox"
+	outboxrepo "auth_service/internal/repository/outbox"
+)
+
+const (
+	cleanupInterval = time.Hour
+	maxBackoff      = time.Hour
+	// maxErrorLength ограничивает текст ошибки, сохраняемый в last_error
+	maxErrorLength = 1000
+)
+
+type Outbox_Relay interface {
+	Run(ctx context.Context, interval time.Duration)
+	Flush(ctx context.Context) (int, error)
+}
+
+// Relay публикует события из outbox во все приемники. Событие считается доставленным,
+// только когда его приняли все приемники; иначе оно целиком отправляется повторно
+// с экспоненциальной задержкой. Доставка — как минимум один раз, без ограничения попыток.
+type Relay struct {
+	repo      *outboxrepo.OutboxRepository
+	sinks     []Sink
+	batchSize int
+	lease     time.Duration
+	baseDelay time.Duration
+	retention time.Duration
+}
+
+func NewRelay(
+	repo *outboxrepo.OutboxRepository,
+	sinks []Sink,
+	batchSize int,
+	lease time.Duration,
+	baseDelay time.Duration,
+	retention time.Duration,
+) *Relay {
+	if batchSize < 1 {
+		batchSize = 100
+	}
+	return &Relay{
+		repo:      repo,
+		sinks:     sinks,
+		batchSize: batchSize,
+		lease:     lease,
+		baseDelay: baseDelay,
+		retention: retention,
+	}
+}
+
+// Run опрашивает outbox с заданным интервалом до отмены ctx
+// и раз в час удаляет доставленные события старше retention.
+func (r *Relay) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := r.Flush(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				log.Printf("outbox relay failed: %v", err)
+			}
+		case <-cleanup.C:
+			removed, err := r.repo.DeletePublished(ctx, time.Now().Add(-r.retention))
+			if err != nil {
+				log.Printf("outbox cleanup failed: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("outbox cleanup removed %d events", removed)
+			}
+		}
+	}
+}
+
+// Flush отправляет готовые события пачками, пока они не закончатся.
+// Возвращает число доставленных событий.
+func (r *Relay) Flush(ctx context.Context) (int, error) {
+	published := 0
+
+	for {
+		events, err := r.repo.Claim(ctx, r.batchSize, r.lease)
+		if err != nil {
+			return published, err
+		}
+
+		// Порядок внутри пачки — порядок записи в outbox
+		sort.Slice(events, func(i, j int) bool { return events[i].ID < events[j].ID })
+
+		for i := range events {
+			if err := ctx.Err(); err != nil {
+				return published, err
+			}
+			if r.deliver(ctx, &events[i]) {
+				published++
+			}
+		}
+
+		if len(events) < r.batchSize {
+			return published, nil
+		}
+	}
+}
+
+// deliver отправляет событие во все приемники и отмечает результат
+func (r *Relay) deliver(ctx context.Context, event *outbox.Event) bool {
+	msg := event.ToMessage()
+
+	var failures []error
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, msg); err != nil {
+			failures = append(failures, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+
+	if len(failures) == 0 {
+		if err := r.repo.MarkPublished(ctx, event.ID); err != nil {
+			// Событие вернется после lease и будет доставлено повторно
+			log.Printf("outbox event %d: %v", event.ID, err)
+			return false
+		}
+		return true
+	}
+
+	reason := errors.Join(failures...).Error()
+	if len(reason) > maxErrorLength {
+		reason = reason[:maxErrorLength]
+	}
+
+	next := time.Now().Add(r.backoff(event.Attempts))
+	if err := r.repo.MarkFailed(ctx, event.ID, reason, next); err != nil {
+		log.Printf("outbox event %d: %v", event.ID, err)
+	}
+	return false
+}
+
+// backoff — baseDelay * 2^attempts, но не больше maxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.baseDelay
+	for i := 0; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+	return delay
+}
@@ -0,0 +1,173 @@
+package outboxService
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"auth_service/internal/config"
+	"auth_service/internal/model/outbox"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	SinkStdout      = "stdout"
+	SinkRedisStream = "redis"
+	SinkWebhook     = "webhook"
+
+	// IdempotencyKeyHeader — заголовок с ID события, по нему получатель отбрасывает повторы
+	IdempotencyKeyHeader = "Idempotency-Key"
+	EventTypeHeader      = "X-Event-Type"
+)
+
+// Sink доставляет событие во внешнюю систему. Ошибка означает, что событие
+// будет отправлено повторно, поэтому получатели должны быть идемпотентны.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, msg outbox.Message) error
+}
+
+// NewSinks создает приемники по списку имен из outbox.sinks
+func NewSinks(names []string, redisClient *redis.Client) ([]Sink, error) {
+	cfg := config.App.Outbox
+	sinks := make([]Sink, 0, len(names))
+
+	for _, name := range names {
+		switch name {
+		case SinkStdout:
+			sinks = append(sinks, NewStdoutSink(os.Stdout))
+		case SinkRedisStream:
+			sinks = append(sinks, NewRedisStreamSink(redisClient, cfg.RedisStream, cfg.RedisStreamMaxLen))
+		case SinkWebhook:
+			if cfg.WebhookURL == "" {
+				return nil, fmt.Errorf("outbox sink %q requires outbox.webhookurl", name)
+			}
+			timeout, err := time.ParseDuration(cfg.WebhookTimeout)
+			if err != nil {
+				timeout = 5 * time.Second
+			}
+			sinks = append(sinks, NewWebhookSink(&http.Client{Timeout: timeout}, cfg.WebhookURL))
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+
+	return sinks, nil
+}
+
+// StdoutSink пишет события построчно в JSON. Для разработки и отладки.
+// Данные события (телефон, email и т.д.) не выводятся — только id, тип и пользователь,
+// чтобы персональные данные не попадали в логи контейнера.
+type StdoutSink struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+type stdoutLine struct {
+	ID     string `json:"id"`
+	Type   string `json:"type"`
+	UserID int64  `json:"user_id"`
+}
+
+func NewStdoutSink(w io.Writer) *StdoutSink {
+	return &StdoutSink{w: w}
+}
+
+func (s *StdoutSink) Name() string { return SinkStdout }
+
+func (s *StdoutSink) Publish(ctx context.Context, msg outbox.Message) error {
+	line, err := json.Marshal(stdoutLine{ID: msg.ID, Type: msg.Type, UserID: msg.UserID})
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, err = s.w.Write(append(line, '\n'))
+	return err
+}
+
+// RedisStreamSink добавляет события в Redis Stream. Поле id записи — ключ идемпотентности,
+// ID записи в потоке при повторной отправке будет другим.
+type RedisStreamSink struct {
+	client *redis.Client
+	stream string
+	maxLen int64
+}
+
+func NewRedisStreamSink(client *redis.Client, stream string, maxLen int64) *RedisStreamSink {
+	return &RedisStreamSink{client: client, stream: stream, maxLen: maxLen}
+}
+
+func (s *RedisStreamSink) Name() string { return SinkRedisStream }
+
+func (s *RedisStreamSink) Publish(ctx context.Context, msg outbox.Message) error {
+	args := &redis.XAddArgs{
+		Stream: s.stream,
+		Values: map[string]interface{}{
+			"id":          msg.ID,
+			"type":        msg.Type,
+			"user_id":     msg.UserID,
+			"occurred_at": msg.OccurredAt.UTC().Format(time.RFC3339Nano),
+			"data":        string(msg.Data),
+		},
+	}
+	if s.maxLen > 0 {
+		args.MaxLen = s.maxLen
+		args.Approx = true
+	}
+
+	if err := s.client.XAdd(ctx, args).Err(); err != nil {
+		return fmt.Errorf("failed to add event to stream %s: %w", s.stream, err)
+	}
+
+	return nil
+}
+
+// WebhookSink отправляет событие POST запросом с JSON телом. Любой ответ вне 2xx — ошибка.
+type WebhookSink struct {
+	client *http.Client
+	url    string
+}
+
+func NewWebhookSink(client *http.Client, url string) *WebhookSink {
+	return &WebhookSink{client: client, url: url}
+}
+
+func (s *WebhookSink) Name() string { return SinkWebhook }
+
+func (s *WebhookSink) Publish(ctx context.Context, msg outbox.Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(IdempotencyKeyHeader, msg.ID)
+	req.Header.Set(EventTypeHeader, msg.Type)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+
+	return nil
+}
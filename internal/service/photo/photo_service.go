@@ -0,0 +1,102 @@
+package photoService
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"auth_service/internal/config"
+	"auth_service/internal/model/user"
+	userrepo "auth_service/internal/repository/user"
+	"auth_service/internal/storage/blob"
+)
+
+// photoPrefix — все фото пользователей лежат под users/<id>/
+const photoPrefix = "users/"
+
+type Photo_GC_Service interface {
+	Run(ctx context.Context, interval time.Duration)
+	Sweep(ctx context.Context) (int, error)
+}
+
+// PhotoGCService удаляет из хранилища объекты, на которые не ссылается
+// ни одна строка users.photo_object: замененные фото, брошенные прямые загрузки,
+// миниатюры размеров, которых больше нет в конфигурации.
+type PhotoGCService struct {
+	userRepo    *userrepo.UserRepository
+	blobs       blob.BlobStore
+	gracePeriod time.Duration
+}
+
+func NewPhotoGCService(userRepo *userrepo.UserRepository, blobs blob.BlobStore, gracePeriod time.Duration) *PhotoGCService {
+	return &PhotoGCService{
+		userRepo:    userRepo,
+		blobs:       blobs,
+		gracePeriod: gracePeriod,
+	}
+}
+
+// Run запускает Sweep с заданным интервалом до отмены ctx
+func (s *PhotoGCService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			removed, err := s.Sweep(ctx)
+			if err != nil {
+				log.Printf("photo gc failed: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("photo gc removed %d orphaned objects", removed)
+			}
+		}
+	}
+}
+
+// Sweep удаляет неиспользуемые объекты старше gracePeriod. Период нужен, чтобы
+// не задеть фото, которое уже загружено, но еще не записано в базу,
+// и прямые загрузки, ожидающие подтверждения.
+func (s *PhotoGCService) Sweep(ctx context.Context) (int, error) {
+	objects, err := s.userRepo.ListPhotoObjects(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	referenced := make(map[string]struct{}, len(objects)*(len(config.App.Photo.ThumbnailSizes)+1))
+	for _, object := range objects {
+		for _, key := range user.PhotoObjectKeys(object) {
+			referenced[key] = struct{}{}
+		}
+	}
+
+	cutoff := time.Now().Add(-s.gracePeriod)
+	removed := 0
+
+	err = s.blobs.List(ctx, photoPrefix, func(info blob.ObjectInfo) error {
+		if strings.HasSuffix(info.Key, "/") || info.LastModified.After(cutoff) {
+			return nil
+		}
+		if _, ok := referenced[info.Key]; ok {
+			return nil
+		}
+
+		if err := s.blobs.Delete(ctx, info.Key); err != nil {
+			log.Printf("photo gc: failed to remove %s: %v", info.Key, err)
+			return nil
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		return removed, fmt.Errorf("failed to list photo objects: %w", err)
+	}
+
+	return removed, nil
+}
@@ -0,0 +1,32 @@
+package profileService
+
+import (
+	"context"
+
+	"auth_service/internal/model/outbox"
+	usercacherepo "auth_service/internal/repository/usercache"
+)
+
+// CacheSinkName — имя приемника outbox, который сбрасывает кэш карточек пользователей
+const CacheSinkName = "user_cache"
+
+// CacheInvalidationSink — приемник outbox: при изменении, удалении или смене фото
+// пользователя удаляет его карточку из кэша GetUsersByIDs. Пока relay выключен,
+// устаревшая карточка живет не дольше TTL кэша.
+type CacheInvalidationSink struct {
+	userCache *usercacherepo.UserCacheRepository
+}
+
+func NewCacheInvalidationSink(userCache *usercacherepo.UserCacheRepository) *CacheInvalidationSink {
+	return &CacheInvalidationSink{userCache: userCache}
+}
+
+func (s *CacheInvalidationSink) Name() string { return CacheSinkName }
+
+func (s *CacheInvalidationSink) Publish(ctx context.Context, msg outbox.Message) error {
+	switch msg.Type {
+	case outbox.EventUserUpdated, outbox.EventUserDeleted, outbox.EventUserPhotoChanged:
+		return s.userCache.Delete(ctx, msg.UserID)
+	}
+	return nil
+}
@@ -1,44 +1,88 @@
 package profileService
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"io"
-	"path/filepath"
+	"log"
+	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
 	"auth_service/internal/config"
+	"auth_service/internal/model/audit"
 	"auth_service/internal/model/request"
+	"auth_service/internal/model/responce"
 	"auth_service/internal/model/user"
-	tokenrepo "auth_service/internal/repository/token"
 	userrepo "auth_service/internal/repository/user"
-	db "auth_service/internal/storage/minio"
+	usercacherepo "auth_service/internal/repository/usercache"
+	auditService "auth_service/internal/service/audit"
+	sessionService "auth_service/internal/service/session"
+	"auth_service/internal/storage/blob"
+	"auth_service/pkg/apperror"
+	"auth_service/pkg/imageproc"
 	"auth_service/pkg/validation"
 
 	//"auth_service/internal/utils"
 
-	"github.com/minio/minio-go/v7"
+	"github.com/google/uuid"
 )
 
+var (
+	ErrInvalidUploadKey = apperror.New(apperror.KindInvalid, "invalid_upload_key", "invalid upload object key")
+	ErrUploadNotFound   = apperror.New(apperror.KindNotFound, "upload_not_found", "uploaded object not found")
+	ErrNoPhoto          = apperror.New(apperror.KindNotFound, "photo_not_found", "profile has no photo")
+	ErrNameRequired     = apperror.New(apperror.KindInvalid, "name_required", "name cannot be removed")
+	ErrEmailTaken       = userrepo.ErrEmailTaken
+	ErrTooManyIDs       = apperror.New(apperror.KindInvalid, "too_many_ids", "too many user ids requested")
+)
+
+// MaxBatchSize — сколько пользователей можно запросить за один вызов GetUsersByIDs
+const MaxBatchSize = 100
+
+var uploadContentTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
 type Profile_Service interface {
 	GetProfile(ctx context.Context, userID int64) *ProfileService
+	GetUsersByIDs(ctx context.Context, ids []int64) ([]user.User, error)
 	UpdateProfile(ctx context.Context, userID int64, req request.UpdateProfileRequest) (*user.User, error)
+	PatchProfile(ctx context.Context, userID int64, req request.PatchProfileRequest) (*user.User, error)
 	DeleteProfile(ctx context.Context, userID int64) error
-	UploadPhoto(ctx context.Context, userID int64, file io.Reader, fileName string, fileSize int64) (string, error)
+	UploadPhoto(ctx context.Context, userID int64, file io.Reader) (*responce.UploadPhotoResponse, error)
+	CreatePhotoUploadURL(ctx context.Context, userID int64, req request.PhotoUploadURLRequest) (*responce.PhotoUploadURLResponse, error)
+	ConfirmPhotoUpload(ctx context.Context, userID int64, objectKey string) (*responce.UploadPhotoResponse, error)
+	DeletePhoto(ctx context.Context, userID int64) error
+	GetActivity(ctx context.Context, userID int64, limit, offset int) ([]audit.Event, int64, error)
 }
 
 type ProfileService struct {
-	userRepo  *userrepo.UserRepository
-	tokenRepo *tokenrepo.TokenRepository
+	userRepo       *userrepo.UserRepository
+	userCache      *usercacherepo.UserCacheRepository
+	sessionService *sessionService.SessionService
+	blobs          blob.BlobStore
+	auditService   *auditService.AuditService
 }
 
-func NewProfileService(userRepo *userrepo.UserRepository, tokenRepo *tokenrepo.TokenRepository) *ProfileService {
+func NewProfileService(
+	userRepo *userrepo.UserRepository,
+	userCache *usercacherepo.UserCacheRepository,
+	sessionService *sessionService.SessionService,
+	blobs blob.BlobStore,
+	auditService *auditService.AuditService,
+) *ProfileService {
 	return &ProfileService{
-		userRepo:  userRepo,
-		tokenRepo: tokenRepo,
+		userRepo:       userRepo,
+		userCache:      userCache,
+		sessionService: sessionService,
+		blobs:          blobs,
+		auditService:   auditService,
 	}
 }
 
@@ -53,6 +97,77 @@ func (s *ProfileService) GetProfile(ctx context.Context, userID int64) (*user.Us
 	return user, nil
 }
 
+// GetUsersByIDs возвращает публичные профили в порядке запроса: заполнены только поля,
+// которые отдает ToPublicResponse. Повторы схлопываются, удаленные и несуществующие
+// ID пропускаются. Профили читаются из кэша, недостающие — одним запросом к базе.
+func (s *ProfileService) GetUsersByIDs(ctx context.Context, ids []int64) ([]user.User, error) {
+	unique := make([]int64, 0, len(ids))
+	seen := make(map[int64]bool, len(ids))
+	for _, id := range ids {
+		if id <= 0 {
+			return nil, apperror.InvalidParameter("ids")
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	if len(unique) > MaxBatchSize {
+		return nil, ErrTooManyIDs.WithDetails(map[string]interface{}{
+			"max": MaxBatchSize,
+		})
+	}
+
+	// Недоступный кэш не должен ронять запрос: читаем все из базы
+	byID, err := s.userCache.GetMany(ctx, unique)
+	if err != nil {
+		log.Printf("failed to read user cache: %v", err)
+		byID = make(map[int64]user.User, len(unique))
+	}
+
+	var missing []int64
+	for _, id := range unique {
+		if _, ok := byID[id]; !ok {
+			missing = append(missing, id)
+		}
+	}
+
+	if len(missing) > 0 {
+		found, err := s.userRepo.GetByIDs(ctx, missing)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range found {
+			found[i] = publicUser(found[i])
+			byID[found[i].ID] = found[i]
+		}
+
+		if err := s.userCache.SetMany(ctx, found); err != nil {
+			log.Printf("failed to fill user cache: %v", err)
+		}
+	}
+
+	users := make([]user.User, 0, len(byID))
+	for _, id := range unique {
+		if u, ok := byID[id]; ok {
+			users = append(users, u)
+		}
+	}
+
+	return users, nil
+}
+
+// publicUser оставляет только поля публичного профиля, как в кэше
+func publicUser(u user.User) user.User {
+	return user.User{
+		ID:          u.ID,
+		Name:        u.Name,
+		DisplayName: u.DisplayName,
+		PhotoObject: u.PhotoObject,
+	}
+}
+
 func (s *ProfileService) UpdateProfile(ctx context.Context, userID int64, req request.UpdateProfileRequest) (*user.User, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
@@ -60,7 +175,7 @@ func (s *ProfileService) UpdateProfile(ctx context.Context, userID int64, req re
 	}
 
 	if req.Email != "" && !validation.ValidateEmail(req.Email) {
-		return nil, errors.New("invalid email format")
+		return nil, validation.ErrInvalidEmail
 	}
 
 	if req.Email != "" && req.Email != user.Email.String {
@@ -69,12 +184,17 @@ func (s *ProfileService) UpdateProfile(ctx context.Context, userID int64, req re
 			return nil, err
 		}
 		if exists {
-			return nil, errors.New("email already in use")
+			return nil, ErrEmailTaken
 		}
 	}
 
-	user.Name = validation.SanitizeInput(req.Name)
-	if req.Email != "" {
+	changed := []string{}
+	if name := validation.SanitizeInput(req.Name); name != user.Name {
+		changed = append(changed, "name")
+		user.Name = name
+	}
+	if req.Email != "" && req.Email != user.Email.String {
+		changed = append(changed, "email")
 		user.Email = sql.NullString{String: req.Email, Valid: true}
 	}
 
@@ -83,46 +203,385 @@ func (s *ProfileService) UpdateProfile(ctx context.Context, userID int64, req re
 		return nil, err
 	}
 
+	if len(changed) > 0 {
+		s.auditService.Record(ctx, userID, userID, audit.ActionProfileUpdate, map[string]interface{}{
+			"fields": changed,
+		})
+	}
+
+	user.Password = ""
+
+	return user, nil
+}
+
+// PatchProfile применяет JSON Merge Patch: отсутствующие поля не меняются,
+// null очищает поле (кроме обязательного name), строка задает новое значение.
+func (s *ProfileService) PatchProfile(ctx context.Context, userID int64, req request.PatchProfileRequest) (*user.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := []string{}
+
+	if req.Name.Set {
+		name := validation.SanitizeInput(req.Name.Value)
+		if req.Name.Null {
+			return nil, ErrNameRequired
+		}
+		if err := validation.ValidateName(name); err != nil {
+			return nil, err
+		}
+		if name != user.Name {
+			changed = append(changed, "name")
+			user.Name = name
+		}
+	}
+
+	if req.DisplayName.Set {
+		value, err := patchString(req.DisplayName, validation.SanitizeInput, validation.ValidateDisplayName)
+		if err != nil {
+			return nil, err
+		}
+		if value != user.DisplayName {
+			changed = append(changed, "display_name")
+			user.DisplayName = value
+		}
+	}
+
+	if req.Email.Set {
+		value, err := patchString(req.Email, strings.TrimSpace, func(email string) error {
+			if !validation.ValidateEmail(email) {
+				return validation.ErrInvalidEmail
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if value != user.Email {
+			if value.Valid {
+				exists, err := s.userRepo.CheckEmailExists(ctx, value.String, userID)
+				if err != nil {
+					return nil, err
+				}
+				if exists {
+					return nil, ErrEmailTaken
+				}
+			}
+			changed = append(changed, "email")
+			user.Email = value
+		}
+	}
+
+	if req.BirthDate.Set {
+		var value sql.NullTime
+		if !req.BirthDate.Null {
+			date, err := validation.ParseBirthDate(strings.TrimSpace(req.BirthDate.Value), time.Now())
+			if err != nil {
+				return nil, err
+			}
+			value = sql.NullTime{Time: date, Valid: true}
+		}
+		if value.Valid != user.BirthDate.Valid || !value.Time.Equal(user.BirthDate.Time) {
+			changed = append(changed, "birth_date")
+			user.BirthDate = value
+		}
+	}
+
+	if req.Locale.Set {
+		var value sql.NullString
+		if !req.Locale.Null {
+			locale, err := validation.NormalizeLocale(strings.TrimSpace(req.Locale.Value))
+			if err != nil {
+				return nil, err
+			}
+			value = sql.NullString{String: locale, Valid: true}
+		}
+		if value != user.Locale {
+			changed = append(changed, "locale")
+			user.Locale = value
+		}
+	}
+
+	if req.Timezone.Set {
+		value, err := patchString(req.Timezone, strings.TrimSpace, validation.ValidateTimezone)
+		if err != nil {
+			return nil, err
+		}
+		if value != user.Timezone {
+			changed = append(changed, "timezone")
+			user.Timezone = value
+		}
+	}
+
+	if req.Bio.Set {
+		value, err := patchString(req.Bio, strings.TrimSpace, validation.ValidateBio)
+		if err != nil {
+			return nil, err
+		}
+		if value.String == "" {
+			value = sql.NullString{}
+		}
+		if value != user.Bio {
+			changed = append(changed, "bio")
+			user.Bio = value
+		}
+	}
+
+	if len(changed) > 0 {
+		if err := s.userRepo.Update(ctx, user); err != nil {
+			return nil, err
+		}
+
+		s.auditService.Record(ctx, userID, userID, audit.ActionProfileUpdate, map[string]interface{}{
+			"fields": changed,
+		})
+	}
+
 	user.Password = ""
 
 	return user, nil
 }
 
+// patchString приводит поле merge patch к значению колонки: null очищает,
+// строка нормализуется и проверяется.
+func patchString(field request.OptionalString, normalize func(string) string, validate func(string) error) (sql.NullString, error) {
+	if field.Null {
+		return sql.NullString{}, nil
+	}
+
+	value := normalize(field.Value)
+	if err := validate(value); err != nil {
+		return sql.NullString{}, err
+	}
+
+	return sql.NullString{String: value, Valid: true}, nil
+}
+
+// DeleteProfile помечает аккаунт удаленным и сразу отзывает все его токены.
+// В течение deletion.graceperiod пользователь может восстановить аккаунт,
+// затем данные обезличивает AccountPurgeService, поэтому фото здесь не удаляется.
 func (s *ProfileService) DeleteProfile(ctx context.Context, userID int64) error {
 	err := s.userRepo.Delete(ctx, userID)
 	if err != nil {
 		return err
 	}
 
-	err = s.tokenRepo.DeleteRefreshToken(ctx, userID)
+	err = s.sessionService.RevokeAll(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, userID, userID, audit.ActionProfileDelete, nil)
 
-	return err
+	return nil
 }
 
-func (s *ProfileService) UploadPhoto(ctx context.Context, userID int64, file io.Reader, fileName string, fileSize int64) (string, error) {
+// UploadPhoto декодирует и перекодирует изображение (см. imageproc.Process),
+// затем сохраняет оригинал и миниатюры рядом: users/<id>/profile_<nanos>[_<size>].<ext>
+func (s *ProfileService) UploadPhoto(ctx context.Context, userID int64, file io.Reader) (*responce.UploadPhotoResponse, error) {
 	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return "", err
+		return nil, err
+	}
+
+	processed, err := processPhoto(file)
+	if err != nil {
+		return nil, err
 	}
 
-	cfg := config.App.Minio
+	return s.savePhoto(ctx, user, processed, nil)
+}
 
-	ext := filepath.Ext(fileName)
-	newFileName := fmt.Sprintf("users/%d/profile_%d%s", userID, time.Now().UnixNano(), ext)
+// CreatePhotoUploadURL выдает presigned PUT URL для загрузки фото напрямую в хранилище.
+// Content-Type и Content-Length входят в подпись, поэтому клиент не может
+// загрузить файл другого типа или размера, чем заявил.
+func (s *ProfileService) CreatePhotoUploadURL(ctx context.Context, userID int64, req request.PhotoUploadURLRequest) (*responce.PhotoUploadURLResponse, error) {
+	if _, err := s.userRepo.GetByID(ctx, userID); err != nil {
+		return nil, err
+	}
 
-	_, err = db.MinioClient.PutObject(ctx, cfg.Bucket, newFileName, file, fileSize, minio.PutObjectOptions{
-		ContentType: "image/" + strings.TrimPrefix(ext, "."),
-	})
+	ext, ok := uploadContentTypes[req.ContentType]
+	if !ok {
+		return nil, imageproc.ErrUnsupportedFormat
+	}
+
+	if req.Size <= 0 || req.Size > config.App.Photo.MaxBytes {
+		return nil, imageproc.ErrTooLarge
+	}
+
+	ttl := parseDuration(config.App.Photo.UploadURLTTL, 15*time.Minute)
+	objectKey := fmt.Sprintf("%s%s%s", uploadPrefix(userID), uuid.NewString(), ext)
+
+	uploadURL, err := s.blobs.PresignPut(ctx, objectKey, req.ContentType, req.Size, ttl)
 	if err != nil {
-		return "", err
+		return nil, fmt.Errorf("failed to presign upload: %w", err)
 	}
 
-	photoURL := fmt.Sprintf("http://%s/%s/%s", cfg.Domain, cfg.Bucket, newFileName)
+	return &responce.PhotoUploadURLResponse{
+		UploadURL: uploadURL,
+		Method:    http.MethodPut,
+		Headers: map[string]string{
+			"Content-Type":   req.ContentType,
+			"Content-Length": strconv.FormatInt(req.Size, 10),
+		},
+		ObjectKey: objectKey,
+		ExpiresAt: time.Now().Add(ttl),
+	}, nil
+}
 
-	user.PhotoURL = sql.NullString{String: photoURL, Valid: true}
-	err = s.userRepo.Update(ctx, user)
+// ConfirmPhotoUpload проверяет загруженный по presigned URL объект так же, как UploadPhoto
+// (декодирование, лимиты, удаление EXIF, миниатюры), сохраняет результат
+// и удаляет исходный объект.
+func (s *ProfileService) ConfirmPhotoUpload(ctx context.Context, userID int64, objectKey string) (*responce.UploadPhotoResponse, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !strings.HasPrefix(objectKey, uploadPrefix(userID)) || strings.Contains(objectKey, "..") {
+		return nil, ErrInvalidUploadKey
+	}
+
+	info, err := s.blobs.Stat(ctx, objectKey)
+	if err != nil {
+		if errors.Is(err, blob.ErrNotFound) {
+			return nil, ErrUploadNotFound
+		}
+		return nil, fmt.Errorf("failed to stat upload: %w", err)
+	}
+
+	if info.Size > config.App.Photo.MaxBytes {
+		s.removeObjects([]string{objectKey})
+		return nil, imageproc.ErrTooLarge
+	}
+
+	object, _, err := s.blobs.Get(ctx, objectKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read upload: %w", err)
+	}
+	defer object.Close()
+
+	processed, err := processPhoto(object)
+	if err != nil {
+		s.removeObjects([]string{objectKey})
+		return nil, err
+	}
+
+	photo, err := s.savePhoto(ctx, user, processed, map[string]interface{}{"direct_upload": true})
+	if err != nil {
+		return nil, err
+	}
+
+	s.removeObjects([]string{objectKey})
+
+	return photo, nil
+}
+
+// DeletePhoto убирает фото из профиля и удаляет оригинал с миниатюрами из хранилища
+func (s *ProfileService) DeletePhoto(ctx context.Context, userID int64) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
 	if err != nil {
-		return "", err
+		return err
+	}
+
+	if !user.PhotoObject.Valid || user.PhotoObject.String == "" {
+		return ErrNoPhoto
+	}
+
+	objects := user.PhotoKeys()
+
+	user.PhotoObject = sql.NullString{}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return err
 	}
-	return photoURL, nil
+
+	s.removeObjects(objects)
+
+	s.auditService.Record(ctx, userID, userID, audit.ActionPhotoDelete, map[string]interface{}{
+		"object": objects[0],
+	})
+
+	return nil
+}
+
+func processPhoto(file io.Reader) (*imageproc.Result, error) {
+	photoCfg := config.App.Photo
+	return imageproc.Process(file, imageproc.Options{
+		MaxBytes:       photoCfg.MaxBytes,
+		MinDimension:   photoCfg.MinDimension,
+		MaxDimension:   photoCfg.MaxDimension,
+		ThumbnailSizes: photoCfg.ThumbnailSizes,
+	})
+}
+
+func (s *ProfileService) savePhoto(ctx context.Context, user *user.User, processed *imageproc.Result, metadata map[string]interface{}) (*responce.UploadPhotoResponse, error) {
+	objectName := fmt.Sprintf("users/%d/profile_%d%s", user.ID, time.Now().UnixNano(), processed.Original.Ext)
+	objects := map[string]imageproc.Image{objectName: processed.Original}
+	for size, thumbnail := range processed.Thumbnails {
+		objects[imageproc.ThumbnailKey(objectName, size)] = thumbnail
+	}
+
+	uploaded := make([]string, 0, len(objects))
+	for name, img := range objects {
+		err := s.blobs.Put(ctx, name, bytes.NewReader(img.Data), int64(len(img.Data)), img.ContentType)
+		if err != nil {
+			s.removeObjects(uploaded)
+			return nil, fmt.Errorf("failed to store photo: %w", err)
+		}
+		uploaded = append(uploaded, name)
+	}
+
+	replaced := user.PhotoKeys()
+
+	user.PhotoObject = sql.NullString{String: objectName, Valid: true}
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		s.removeObjects(uploaded)
+		return nil, err
+	}
+
+	s.removeObjects(replaced)
+
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+	}
+	metadata["object"] = objectName
+	metadata["size"] = len(processed.Original.Data)
+	metadata["width"] = processed.Original.Width
+	metadata["height"] = processed.Original.Height
+	s.auditService.Record(ctx, user.ID, user.ID, audit.ActionPhotoUpload, metadata)
+
+	resp := user.ToResponse()
+	return &responce.UploadPhotoResponse{
+		PhotoURL:        resp.PhotoURL,
+		PhotoThumbnails: resp.PhotoThumbnails,
+	}, nil
+}
+
+func uploadPrefix(userID int64) string {
+	return fmt.Sprintf("users/%d/uploads/", userID)
+}
+
+func parseDuration(durationStr string, fallback time.Duration) time.Duration {
+	dur, err := time.ParseDuration(durationStr)
+	if err != nil {
+		return fallback
+	}
+	return dur
+}
+
+func (s *ProfileService) removeObjects(names []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	for _, name := range names {
+		if err := s.blobs.Delete(ctx, name); err != nil {
+			log.Printf("failed to remove photo object %s: %v", name, err)
+		}
+	}
+}
+
+func (s *ProfileService) GetActivity(ctx context.Context, userID int64, limit, offset int) ([]audit.Event, int64, error) {
+	return s.auditService.ListUserActivity(ctx, userID, limit, offset)
 }
@@ -0,0 +1,51 @@
+package sessionService
+
+import (
+	"context"
+	"time"
+
+	tokenrepo "auth_service/internal/repository/token"
+	userrepo "auth_service/internal/repository/user"
+)
+
+type Session_Service interface {
+	RevokeAll(ctx context.Context, userID int64) error
+}
+
+// SessionService завершает все сессии пользователя: удаляет refresh токен и
+// сдвигает отметку tokens_valid_after, по которой AuthMiddleware отклоняет access токены.
+type SessionService struct {
+	userRepo  *userrepo.UserRepository
+	tokenRepo *tokenrepo.TokenRepository
+}
+
+func NewSessionService(userRepo *userrepo.UserRepository, tokenRepo *tokenrepo.TokenRepository) *SessionService {
+	return &SessionService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+	}
+}
+
+// RevokeAll отзывает все токены, выданные до момента вызова, включая текущую секунду.
+// Новые токены выдаются с iat не раньше отметки (см. AuthService.generateTokens).
+func (s *SessionService) RevokeAll(ctx context.Context, userID int64) error {
+	validAfter := revocationCutoff(time.Now())
+
+	// Сначала Postgres: если Redis недоступен, AuthMiddleware прочитает отметку из базы
+	if err := s.userRepo.SetTokensValidAfter(ctx, userID, validAfter); err != nil {
+		return err
+	}
+
+	if err := s.tokenRepo.SetTokensValidAfter(ctx, userID, validAfter); err != nil {
+		return err
+	}
+
+	return s.tokenRepo.DeleteRefreshToken(ctx, userID)
+}
+
+// revocationCutoff округляет момент отзыва вверх до целой секунды. iat в JWT хранится
+// с точностью до секунды, и токен, выданный в ту же секунду до отзыва (например,
+// украденный и обновленный перед "выйти везде"), при округлении вниз остался бы действительным.
+func revocationCutoff(now time.Time) time.Time {
+	return now.Truncate(time.Second).Add(time.Second)
+}
@@ -0,0 +1,72 @@
+package sessionService
+
+import (
+	"testing"
+	"time"
+
+	"auth_service/internal/config"
+	"auth_service/pkg/jwt"
+)
+
+// revoked повторяет проверку middleware.Authenticate
+func revoked(claims *jwt.Claims, validAfter time.Time) bool {
+	return claims.IssuedAt == nil || claims.IssuedAt.Time.Before(validAfter)
+}
+
+func TestRevocationCutoffCoversSameSecond(t *testing.T) {
+	config.App.JWT.AccessSecret = "test-secret"
+	config.App.JWT.AccessTTL = "15m"
+
+	second := time.Now().Truncate(time.Second)
+
+	tests := []struct {
+		name        string
+		issued      time.Duration
+		revoked     time.Duration
+		wantRevoked bool
+	}{
+		{"issued earlier in the same second", 100 * time.Millisecond, 900 * time.Millisecond, true},
+		{"issued at the same instant", 500 * time.Millisecond, 500 * time.Millisecond, true},
+		{"revoked on a second boundary", 0, 0, true},
+		{"issued a second before", -time.Second, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwt.GenerateAccessToken(1, nil, second.Add(tt.issued))
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := jwt.ValidateAccessToken(token)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			cutoff := revocationCutoff(second.Add(tt.revoked))
+			if got := revoked(claims, cutoff); got != tt.wantRevoked {
+				t.Fatalf("revoked = %v, want %v (iat %v, cutoff %v)", got, tt.wantRevoked, claims.IssuedAt.Time, cutoff)
+			}
+		})
+	}
+}
+
+func TestTokenIssuedAtCutoffStaysValid(t *testing.T) {
+	config.App.JWT.AccessSecret = "test-secret"
+	config.App.JWT.AccessTTL = "15m"
+
+	cutoff := revocationCutoff(time.Now())
+
+	// Так выдает токен AuthService.generateTokens сразу после отзыва
+	token, err := jwt.GenerateAccessToken(1, nil, cutoff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := jwt.ValidateAccessToken(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked(claims, cutoff) {
+		t.Fatalf("token issued at cutoff %v is revoked", cutoff)
+	}
+}
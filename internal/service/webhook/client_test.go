@@ -0,0 +1,77 @@
+package webhookService
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestIsPublicIP(t *testing.T) {
+	tests := []struct {
+		ip   string
+		want bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"100.64.0.1", false},
+		{"0.0.0.0", false},
+		{"fd00::1", false},
+		{"fe80::1", false},
+		{"::ffff:127.0.0.1", false},
+		{"::ffff:10.0.0.1", false},
+	}
+
+	for _, tt := range tests {
+		if got := isPublicIP(net.ParseIP(tt.ip)); got != tt.want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", tt.ip, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeURLRejectsPrivateTargets(t *testing.T) {
+	for _, raw := range []string{
+		"http://127.0.0.1:8080/hook",
+		"http://localhost/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"https://[::1]/hook",
+		"http://10.0.0.5/hook",
+	} {
+		if _, err := normalizeURL(context.Background(), raw); !errors.Is(err, ErrURLNotAllowed) {
+			t.Errorf("normalizeURL(%q) err = %v, want %v", raw, err, ErrURLNotAllowed)
+		}
+	}
+
+	if _, err := normalizeURL(context.Background(), "ftp://93.184.216.34/hook"); !errors.Is(err, ErrInvalidURL) {
+		t.Errorf("ftp url err = %v, want %v", err, ErrInvalidURL)
+	}
+	if got, err := normalizeURL(context.Background(), " https://93.184.216.34/hook "); err != nil || got != "https://93.184.216.34/hook" {
+		t.Errorf("public url = %q, %v", got, err)
+	}
+}
+
+func TestHTTPClientRefusesLoopbackAndRedirects(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	client := NewHTTPClient(time.Second)
+	if _, err := client.Get(server.URL); err == nil || called {
+		t.Fatalf("request to loopback %s was not blocked", server.URL)
+	}
+
+	if err := client.CheckRedirect(nil, nil); !errors.Is(err, http.ErrUseLastResponse) {
+		t.Errorf("CheckRedirect = %v, want %v", err, http.ErrUseLastResponse)
+	}
+}
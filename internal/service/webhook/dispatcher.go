@@ -0,0 +1,237 @@
+package webhookService
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"auth_service/internal/model/webhook"
+	webhookrepo "auth_service/internal/repository/webhook"
+	outboxService "auth_service/internal/service/outbox"
+)
+
+const (
+	// SignatureHeader — "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader — время отправки в Unix секундах. Получатель отклоняет запросы
+	// со старым временем, чтобы перехваченный запрос нельзя было повторить.
+	TimestampHeader = "X-Webhook-Timestamp"
+
+	cleanupInterval = time.Hour
+	// maxResponseLength ограничивает тело ответа получателя, сохраняемое в журнале
+	maxResponseLength = 1024
+)
+
+// Sign возвращает значение заголовка X-Webhook-Signature для тела запроса
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// DispatcherConfig — параметры отправки, см. секцию webhooks конфигурации
+type DispatcherConfig struct {
+	BatchSize     int
+	Concurrency   int
+	Lease         time.Duration
+	MaxAttempts   int
+	RetryDelay    time.Duration
+	MaxRetryDelay time.Duration
+	Retention     time.Duration
+}
+
+type Webhook_Dispatcher interface {
+	Run(ctx context.Context, interval time.Duration)
+	Dispatch(ctx context.Context) (int, error)
+}
+
+// Dispatcher отправляет доставки на webhook. Неуспешная доставка повторяется
+// с экспоненциальной задержкой, после MaxAttempts попыток переходит в статус dead
+// и ждет ручного повтора через API.
+type Dispatcher struct {
+	repo   webhookrepo.Webhook_Repository
+	client *http.Client
+	cfg    DispatcherConfig
+}
+
+func NewDispatcher(repo webhookrepo.Webhook_Repository, client *http.Client, cfg DispatcherConfig) *Dispatcher {
+	if cfg.BatchSize < 1 {
+		cfg.BatchSize = 50
+	}
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+	if cfg.MaxAttempts < 1 {
+		cfg.MaxAttempts = 1
+	}
+	return &Dispatcher{repo: repo, client: client, cfg: cfg}
+}
+
+// Run отправляет доставки с заданным интервалом до отмены ctx
+// и раз в час удаляет завершенные доставки старше Retention.
+func (d *Dispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	cleanup := time.NewTicker(cleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := d.Dispatch(ctx); err != nil && ctx.Err() == nil {
+				log.Printf("webhook dispatch failed: %v", err)
+			}
+		case <-cleanup.C:
+			removed, err := d.repo.DeleteDeliveries(ctx, time.Now().Add(-d.cfg.Retention))
+			if err != nil {
+				log.Printf("webhook cleanup failed: %v", err)
+				continue
+			}
+			if removed > 0 {
+				log.Printf("webhook cleanup removed %d deliveries", removed)
+			}
+		}
+	}
+}
+
+// Dispatch отправляет готовые доставки пачками, пока они не закончатся.
+// Возвращает число успешных доставок.
+func (d *Dispatcher) Dispatch(ctx context.Context) (int, error) {
+	succeeded := 0
+
+	for {
+		deliveries, err := d.repo.ClaimDeliveries(ctx, d.cfg.BatchSize, d.cfg.Lease)
+		if err != nil {
+			return succeeded, err
+		}
+
+		var (
+			wg  sync.WaitGroup
+			mu  sync.Mutex
+			sem = make(chan struct{}, d.cfg.Concurrency)
+		)
+		for i := range deliveries {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(delivery *webhook.Delivery) {
+				defer wg.Done()
+				defer func() { <-sem }()
+
+				if d.deliver(ctx, delivery) {
+					mu.Lock()
+					succeeded++
+					mu.Unlock()
+				}
+			}(&deliveries[i])
+		}
+		wg.Wait()
+
+		if len(deliveries) < d.cfg.BatchSize || ctx.Err() != nil {
+			return succeeded, ctx.Err()
+		}
+	}
+}
+
+// deliver выполняет одну попытку и сохраняет ее результат
+func (d *Dispatcher) deliver(ctx context.Context, delivery *webhook.Delivery) bool {
+	started := time.Now()
+	statusCode, body, err := d.send(ctx, delivery)
+
+	attempt := &webhook.Attempt{
+		DeliveryID: delivery.ID,
+		DurationMS: int(time.Since(started).Milliseconds()),
+	}
+	if statusCode > 0 {
+		attempt.StatusCode = sql.NullInt64{Int64: int64(statusCode), Valid: true}
+	}
+	if body != "" {
+		attempt.Response = sql.NullString{String: body, Valid: true}
+	}
+
+	delivery.Attempts++
+	delivery.LastStatusCode = attempt.StatusCode
+
+	ok := err == nil
+	switch {
+	case ok:
+		delivery.Status = webhook.DeliverySucceeded
+		delivery.LastError = sql.NullString{}
+		delivery.DeliveredAt = sql.NullTime{Time: time.Now(), Valid: true}
+	case delivery.Attempts >= d.cfg.MaxAttempts:
+		delivery.Status = webhook.DeliveryDead
+	default:
+		delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+	}
+	if err != nil {
+		attempt.Error = sql.NullString{String: err.Error(), Valid: true}
+		delivery.LastError = attempt.Error
+	}
+
+	// ctx мог быть отменен при остановке: результат попытки все равно сохраняем
+	if err := d.repo.RecordAttempt(context.WithoutCancel(ctx), delivery, attempt); err != nil {
+		log.Printf("webhook delivery %d: %v", delivery.ID, err)
+	}
+
+	return ok
+}
+
+// send подписывает и отправляет тело доставки. Ответ вне 2xx — ошибка.
+func (d *Dispatcher) send(ctx context.Context, delivery *webhook.Delivery) (int, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.EndpointURL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to build request: %w", err)
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "auth-service-webhooks/1")
+	req.Header.Set(outboxService.IdempotencyKeyHeader, delivery.EventID)
+	req.Header.Set(outboxService.EventTypeHeader, delivery.EventType)
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.EndpointSecret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, "", fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseLength))
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	// Postgres не примет в TEXT невалидный UTF-8 и нулевые байты
+	body := strings.ReplaceAll(strings.ToValidUTF8(string(raw), "\uFFFD"), "\x00", "")
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, body, fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+
+	return resp.StatusCode, body, nil
+}
+
+// backoff — RetryDelay * 2^(attempts-1), но не больше MaxRetryDelay
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.cfg.RetryDelay
+	for i := 1; i < attempts && delay < d.cfg.MaxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > d.cfg.MaxRetryDelay {
+		delay = d.cfg.MaxRetryDelay
+	}
+	return delay
+}
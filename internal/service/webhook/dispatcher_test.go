@@ -0,0 +1,580 @@
+package webhookService
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"auth_service/internal/model/audit"
+	"auth_service/internal/model/outbox"
+	"auth_service/internal/model/webhook"
+	webhookrepo "auth_service/internal/repository/webhook"
+)
+
+const testSecret = "whsec_test"
+
+// memRepo — webhookrepo.Webhook_Repository в памяти. Повторяет семантику SQL:
+// UNIQUE (endpoint_id, event_id) с ON CONFLICT DO NOTHING, выдачу только готовых
+// pending доставок на активные webhook и аренду через next_attempt_at.
+type memRepo struct {
+	mu         sync.Mutex
+	endpoints  map[int64]*webhook.Endpoint
+	deliveries map[int64]*webhook.Delivery
+	attempts   []webhook.Attempt
+	nextID     int64
+}
+
+func newMemRepo() *memRepo {
+	return &memRepo{
+		endpoints:  map[int64]*webhook.Endpoint{},
+		deliveries: map[int64]*webhook.Delivery{},
+	}
+}
+
+func (r *memRepo) id() int64 {
+	r.nextID++
+	return r.nextID
+}
+
+func (r *memRepo) CreateEndpoint(_ context.Context, endpoint *webhook.Endpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endpoint.ID = r.id()
+	endpoint.CreatedAt = time.Now()
+	endpoint.UpdatedAt = endpoint.CreatedAt
+	copied := *endpoint
+	r.endpoints[endpoint.ID] = &copied
+	return nil
+}
+
+func (r *memRepo) GetEndpoint(_ context.Context, id int64) (*webhook.Endpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endpoint, ok := r.endpoints[id]
+	if !ok {
+		return nil, webhookrepo.ErrEndpointNotFound
+	}
+	copied := *endpoint
+	return &copied, nil
+}
+
+func (r *memRepo) ListEndpoints(_ context.Context) ([]webhook.Endpoint, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	endpoints := []webhook.Endpoint{}
+	for _, endpoint := range r.endpoints {
+		endpoints = append(endpoints, *endpoint)
+	}
+	sort.Slice(endpoints, func(i, j int) bool { return endpoints[i].ID < endpoints[j].ID })
+	return endpoints, nil
+}
+
+func (r *memRepo) UpdateEndpoint(_ context.Context, endpoint *webhook.Endpoint) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.endpoints[endpoint.ID]; !ok {
+		return webhookrepo.ErrEndpointNotFound
+	}
+	endpoint.UpdatedAt = time.Now()
+	copied := *endpoint
+	r.endpoints[endpoint.ID] = &copied
+	return nil
+}
+
+func (r *memRepo) DeleteEndpoint(_ context.Context, id int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.endpoints[id]; !ok {
+		return webhookrepo.ErrEndpointNotFound
+	}
+	delete(r.endpoints, id)
+	for deliveryID, delivery := range r.deliveries {
+		if delivery.EndpointID == id {
+			delete(r.deliveries, deliveryID)
+		}
+	}
+	return nil
+}
+
+func (r *memRepo) EnqueueDeliveries(_ context.Context, eventID, eventType string, payload []byte) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var created int64
+	for _, endpoint := range r.endpoints {
+		if !endpoint.IsActive || !containsString(endpoint.EventTypes, eventType) {
+			continue
+		}
+
+		duplicate := false
+		for _, delivery := range r.deliveries {
+			if delivery.EndpointID == endpoint.ID && delivery.EventID == eventID {
+				duplicate = true
+				break
+			}
+		}
+		if duplicate {
+			continue
+		}
+
+		now := time.Now()
+		id := r.id()
+		r.deliveries[id] = &webhook.Delivery{
+			ID:            id,
+			EndpointID:    endpoint.ID,
+			EventID:       eventID,
+			EventType:     eventType,
+			Payload:       append(json.RawMessage(nil), payload...),
+			Status:        webhook.DeliveryPending,
+			NextAttemptAt: now,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+		}
+		created++
+	}
+	return created, nil
+}
+
+func (r *memRepo) ClaimDeliveries(_ context.Context, limit int, lease time.Duration) ([]webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	var ids []int64
+	for id, delivery := range r.deliveries {
+		endpoint := r.endpoints[delivery.EndpointID]
+		if delivery.Status == webhook.DeliveryPending && !delivery.NextAttemptAt.After(now) && endpoint != nil && endpoint.IsActive {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	if len(ids) > limit {
+		ids = ids[:limit]
+	}
+
+	claimed := make([]webhook.Delivery, 0, len(ids))
+	for _, id := range ids {
+		delivery := r.deliveries[id]
+		delivery.NextAttemptAt = now.Add(lease)
+
+		copied := *delivery
+		copied.EndpointURL = r.endpoints[delivery.EndpointID].URL
+		copied.EndpointSecret = r.endpoints[delivery.EndpointID].Secret
+		claimed = append(claimed, copied)
+	}
+	return claimed, nil
+}
+
+func (r *memRepo) RecordAttempt(_ context.Context, delivery *webhook.Delivery, attempt *webhook.Attempt) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, ok := r.deliveries[delivery.ID]
+	if !ok {
+		return webhookrepo.ErrDeliveryNotFound
+	}
+
+	attempt.ID = r.id()
+	attempt.CreatedAt = time.Now()
+	r.attempts = append(r.attempts, *attempt)
+
+	stored.Status = delivery.Status
+	stored.Attempts = delivery.Attempts
+	stored.NextAttemptAt = delivery.NextAttemptAt
+	stored.LastStatusCode = delivery.LastStatusCode
+	stored.LastError = delivery.LastError
+	stored.DeliveredAt = delivery.DeliveredAt
+	stored.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *memRepo) GetDelivery(_ context.Context, endpointID, deliveryID int64) (*webhook.Delivery, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery, ok := r.deliveries[deliveryID]
+	if !ok || delivery.EndpointID != endpointID {
+		return nil, webhookrepo.ErrDeliveryNotFound
+	}
+	copied := *delivery
+	return &copied, nil
+}
+
+func (r *memRepo) ListDeliveries(_ context.Context, endpointID int64, status string, limit, offset int) ([]webhook.Delivery, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	deliveries := []webhook.Delivery{}
+	for _, delivery := range r.deliveries {
+		if delivery.EndpointID == endpointID && (status == "" || delivery.Status == status) {
+			deliveries = append(deliveries, *delivery)
+		}
+	}
+	sort.Slice(deliveries, func(i, j int) bool { return deliveries[i].ID > deliveries[j].ID })
+
+	total := int64(len(deliveries))
+	if offset >= len(deliveries) {
+		return []webhook.Delivery{}, total, nil
+	}
+	deliveries = deliveries[offset:]
+	if len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+	return deliveries, total, nil
+}
+
+func (r *memRepo) ListAttempts(_ context.Context, deliveryID int64) ([]webhook.Attempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempts := []webhook.Attempt{}
+	for _, attempt := range r.attempts {
+		if attempt.DeliveryID == deliveryID {
+			attempts = append(attempts, attempt)
+		}
+	}
+	return attempts, nil
+}
+
+func (r *memRepo) RequeueDelivery(_ context.Context, endpointID, deliveryID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delivery, ok := r.deliveries[deliveryID]
+	if !ok || delivery.EndpointID != endpointID || delivery.Status == webhook.DeliveryPending {
+		return webhookrepo.ErrDeliveryNotFound
+	}
+	delivery.Status = webhook.DeliveryPending
+	delivery.Attempts = 0
+	delivery.NextAttemptAt = time.Now()
+	delivery.UpdatedAt = time.Now()
+	return nil
+}
+
+func (r *memRepo) DeleteDeliveries(_ context.Context, before time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var removed int64
+	for id, delivery := range r.deliveries {
+		if delivery.Status != webhook.DeliveryPending && delivery.CreatedAt.Before(before) {
+			delete(r.deliveries, id)
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// delivery возвращает единственную доставку репозитория
+func (r *memRepo) delivery(t *testing.T) webhook.Delivery {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.deliveries) != 1 {
+		t.Fatalf("expected 1 delivery, got %d", len(r.deliveries))
+	}
+	for _, delivery := range r.deliveries {
+		return *delivery
+	}
+	return webhook.Delivery{}
+}
+
+// makeDue переносит повтор всех доставок на текущий момент
+func (r *memRepo) makeDue() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, delivery := range r.deliveries {
+		delivery.NextAttemptAt = time.Now().Add(-time.Second)
+	}
+}
+
+// nopAudit — auditService.Audit_Service, который запоминает действия
+type nopAudit struct {
+	mu      sync.Mutex
+	actions []string
+}
+
+func (a *nopAudit) Record(_ context.Context, _, _ int64, action string, _ map[string]interface{}) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.actions = append(a.actions, action)
+}
+
+func (a *nopAudit) ListUserActivity(context.Context, int64, int, int) ([]audit.Event, int64, error) {
+	return nil, 0, nil
+}
+
+func (a *nopAudit) Query(context.Context, audit.Filter) ([]audit.Event, int64, error) {
+	return nil, 0, nil
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// setup регистрирует webhook на адрес тестового сервера и ставит в очередь одно событие
+func setup(t *testing.T, handler http.HandlerFunc, cfg DispatcherConfig) (*memRepo, *Dispatcher, *httptest.Server) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	repo := newMemRepo()
+	endpoint := &webhook.Endpoint{
+		URL:        server.URL,
+		Secret:     testSecret,
+		EventTypes: webhook.EventTypes{outbox.EventUserUpdated},
+		IsActive:   true,
+	}
+	if err := repo.CreateEndpoint(context.Background(), endpoint); err != nil {
+		t.Fatal(err)
+	}
+
+	publish(t, repo, "event-1")
+
+	// Адрес httptest — loopback, поэтому клиент сервера, а не NewHTTPClient
+	return repo, NewDispatcher(repo, server.Client(), cfg), server
+}
+
+func publish(t *testing.T, repo *memRepo, eventID string) {
+	t.Helper()
+
+	msg := outbox.Message{
+		ID:         eventID,
+		Type:       outbox.EventUserUpdated,
+		UserID:     42,
+		OccurredAt: time.Now(),
+		Data:       json.RawMessage(`{"name":"Ivan"}`),
+	}
+	if err := NewFanoutSink(repo).Publish(context.Background(), msg); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testConfig() DispatcherConfig {
+	return DispatcherConfig{
+		BatchSize:     10,
+		Concurrency:   2,
+		Lease:         time.Minute,
+		MaxAttempts:   3,
+		RetryDelay:    time.Minute,
+		MaxRetryDelay: time.Hour,
+	}
+}
+
+func TestDispatchSignsRequest(t *testing.T) {
+	type received struct {
+		signature string
+		timestamp string
+		eventID   string
+		eventType string
+		body      []byte
+	}
+	requests := make(chan received, 1)
+
+	repo, dispatcher, _ := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{
+			signature: r.Header.Get(SignatureHeader),
+			timestamp: r.Header.Get(TimestampHeader),
+			eventID:   r.Header.Get("Idempotency-Key"),
+			eventType: r.Header.Get("X-Event-Type"),
+			body:      body,
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}, testConfig())
+
+	succeeded, err := dispatcher.Dispatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if succeeded != 1 {
+		t.Fatalf("succeeded = %d, want 1", succeeded)
+	}
+
+	req := <-requests
+	timestamp, err := strconv.ParseInt(req.timestamp, 10, 64)
+	if err != nil {
+		t.Fatalf("invalid %s %q", TimestampHeader, req.timestamp)
+	}
+	if want := Sign(testSecret, timestamp, req.body); req.signature != want {
+		t.Errorf("signature = %q, want %q", req.signature, want)
+	}
+	if req.eventID != "event-1" || req.eventType != outbox.EventUserUpdated {
+		t.Errorf("event headers = %q %q", req.eventID, req.eventType)
+	}
+
+	delivery := repo.delivery(t)
+	if delivery.Status != webhook.DeliverySucceeded || !delivery.DeliveredAt.Valid {
+		t.Errorf("status = %s, delivered_at = %v", delivery.Status, delivery.DeliveredAt)
+	}
+}
+
+func TestDispatchSchedulesRetryWithBackoff(t *testing.T) {
+	cfg := testConfig()
+	repo, dispatcher, _ := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}, cfg)
+
+	for attempt := 1; attempt <= 2; attempt++ {
+		before := time.Now()
+		if _, err := dispatcher.Dispatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		after := time.Now()
+
+		delivery := repo.delivery(t)
+		if delivery.Status != webhook.DeliveryPending || delivery.Attempts != attempt {
+			t.Fatalf("attempt %d: status = %s, attempts = %d", attempt, delivery.Status, delivery.Attempts)
+		}
+		if delivery.LastStatusCode.Int64 != http.StatusServiceUnavailable {
+			t.Errorf("attempt %d: last_status_code = %d", attempt, delivery.LastStatusCode.Int64)
+		}
+
+		delay := dispatcher.backoff(attempt)
+		if delivery.NextAttemptAt.Before(before.Add(delay)) || delivery.NextAttemptAt.After(after.Add(delay)) {
+			t.Errorf("attempt %d: next_attempt_at = %v, want now + %v", attempt, delivery.NextAttemptAt, delay)
+		}
+
+		// Повтор еще не наступил: доставка не уходит
+		if succeeded, _ := dispatcher.Dispatch(context.Background()); succeeded != 0 || repo.delivery(t).Attempts != attempt {
+			t.Fatalf("attempt %d: delivery was sent before next_attempt_at", attempt)
+		}
+		repo.makeDue()
+	}
+
+	if got, want := dispatcher.backoff(2), 2*cfg.RetryDelay; got != want {
+		t.Errorf("backoff(2) = %v, want %v", got, want)
+	}
+	if got := dispatcher.backoff(100); got != cfg.MaxRetryDelay {
+		t.Errorf("backoff(100) = %v, want %v", got, cfg.MaxRetryDelay)
+	}
+}
+
+func TestDispatchMarksDeadAfterMaxAttempts(t *testing.T) {
+	var calls atomic.Int32
+	cfg := testConfig()
+	repo, dispatcher, _ := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		w.WriteHeader(http.StatusInternalServerError)
+	}, cfg)
+
+	for i := 0; i < cfg.MaxAttempts; i++ {
+		if _, err := dispatcher.Dispatch(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		repo.makeDue()
+	}
+
+	delivery := repo.delivery(t)
+	if delivery.Status != webhook.DeliveryDead || delivery.Attempts != cfg.MaxAttempts {
+		t.Fatalf("status = %s, attempts = %d, want dead after %d", delivery.Status, delivery.Attempts, cfg.MaxAttempts)
+	}
+
+	if _, err := dispatcher.Dispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := int(calls.Load()); got != cfg.MaxAttempts {
+		t.Errorf("webhook called %d times, want %d", got, cfg.MaxAttempts)
+	}
+
+	attempts, _ := repo.ListAttempts(context.Background(), delivery.ID)
+	if len(attempts) != cfg.MaxAttempts {
+		t.Errorf("attempt log has %d entries, want %d", len(attempts), cfg.MaxAttempts)
+	}
+}
+
+func TestRetryDeliveryRequeuesDeadDelivery(t *testing.T) {
+	var healthy atomic.Bool
+	cfg := testConfig()
+	cfg.MaxAttempts = 1
+	repo, dispatcher, _ := setup(t, func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}, cfg)
+
+	if _, err := dispatcher.Dispatch(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	dead := repo.delivery(t)
+	if dead.Status != webhook.DeliveryDead {
+		t.Fatalf("status = %s, want dead", dead.Status)
+	}
+
+	audits := &nopAudit{}
+	service := NewWebhookService(repo, audits)
+	if err := service.RetryDelivery(context.Background(), 1, dead.EndpointID, dead.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	requeued := repo.delivery(t)
+	if requeued.Status != webhook.DeliveryPending || requeued.Attempts != 0 {
+		t.Fatalf("status = %s, attempts = %d after retry", requeued.Status, requeued.Attempts)
+	}
+	if err := service.RetryDelivery(context.Background(), 1, dead.EndpointID, dead.ID); !errors.Is(err, ErrDeliveryPending) {
+		t.Errorf("second retry err = %v, want %v", err, ErrDeliveryPending)
+	}
+	if !containsString(audits.actions, audit.ActionAdminWebhookRetry) {
+		t.Errorf("retry was not audited: %v", audits.actions)
+	}
+
+	healthy.Store(true)
+	succeeded, err := dispatcher.Dispatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if succeeded != 1 || repo.delivery(t).Status != webhook.DeliverySucceeded {
+		t.Errorf("requeued delivery was not sent: succeeded = %d, status = %s", succeeded, repo.delivery(t).Status)
+	}
+}
+
+func TestEnqueueDeliveriesIgnoresRepeatedEvent(t *testing.T) {
+	repo, _, _ := setup(t, func(w http.ResponseWriter, r *http.Request) {}, testConfig())
+
+	// Relay публикует событие повторно, если другой приемник не ответил
+	publish(t, repo, "event-1")
+	publish(t, repo, "event-1")
+
+	delivery := repo.delivery(t)
+	if delivery.EventID != "event-1" {
+		t.Errorf("event_id = %q", delivery.EventID)
+	}
+
+	created, err := repo.EnqueueDeliveries(context.Background(), "event-1", outbox.EventUserUpdated, []byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 0 {
+		t.Errorf("EnqueueDeliveries created %d deliveries for a repeated event", created)
+	}
+
+	publish(t, repo, "event-2")
+	deliveries, total, _ := repo.ListDeliveries(context.Background(), delivery.EndpointID, "", 10, 0)
+	if total != 2 || len(deliveries) != 2 {
+		t.Errorf("got %d deliveries after a new event, want 2", total)
+	}
+}
@@ -0,0 +1,36 @@
+package webhookService
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"auth_service/internal/model/outbox"
+	webhookrepo "auth_service/internal/repository/webhook"
+)
+
+// SinkName — имя приемника outbox, который раскладывает события по webhook
+const SinkName = "webhooks"
+
+// FanoutSink — приемник outbox: для каждого события создает доставки на все
+// подписанные webhook. Повторная публикация того же события дублей не создает,
+// а отправка и повторы на каждый webhook идут независимо в Dispatcher.
+type FanoutSink struct {
+	repo webhookrepo.Webhook_Repository
+}
+
+func NewFanoutSink(repo webhookrepo.Webhook_Repository) *FanoutSink {
+	return &FanoutSink{repo: repo}
+}
+
+func (s *FanoutSink) Name() string { return SinkName }
+
+func (s *FanoutSink) Publish(ctx context.Context, msg outbox.Message) error {
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	_, err = s.repo.EnqueueDeliveries(ctx, msg.ID, msg.Type, payload)
+	return err
+}
@@ -0,0 +1,275 @@
+package webhookService
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"auth_service/internal/model/audit"
+	"auth_service/internal/model/outbox"
+	"auth_service/internal/model/request"
+	"auth_service/internal/model/webhook"
+	webhookrepo "auth_service/internal/repository/webhook"
+	auditService "auth_service/internal/service/audit"
+	"auth_service/pkg/apperror"
+	"auth_service/pkg/validation"
+)
+
+const secretPrefix = "whsec_"
+
+var (
+	ErrInvalidURL       = apperror.New(apperror.KindInvalid, "invalid_webhook_url", "url must be an absolute http or https URL")
+	ErrURLNotAllowed    = apperror.New(apperror.KindInvalid, "webhook_url_not_allowed", "url must resolve to a public address")
+	ErrUnknownEventType = apperror.New(apperror.KindInvalid, "unknown_event_type", "unknown event type")
+	ErrDeliveryPending  = apperror.New(apperror.KindConflict, "webhook_delivery_pending", "delivery is already pending")
+	ErrEndpointNotFound = webhookrepo.ErrEndpointNotFound
+	ErrDeliveryNotFound = webhookrepo.ErrDeliveryNotFound
+)
+
+type Webhook_Service interface {
+	CreateEndpoint(ctx context.Context, actorID int64, req request.CreateWebhookRequest) (*webhook.Endpoint, error)
+	ListEndpoints(ctx context.Context) ([]webhook.Endpoint, error)
+	GetEndpoint(ctx context.Context, id int64) (*webhook.Endpoint, error)
+	UpdateEndpoint(ctx context.Context, actorID, id int64, req request.UpdateWebhookRequest) (*webhook.Endpoint, error)
+	DeleteEndpoint(ctx context.Context, actorID, id int64) error
+	RotateSecret(ctx context.Context, actorID, id int64) (*webhook.Endpoint, error)
+	ListDeliveries(ctx context.Context, endpointID int64, status string, limit, offset int) ([]webhook.Delivery, int64, error)
+	GetDelivery(ctx context.Context, endpointID, deliveryID int64) (*webhook.Delivery, []webhook.Attempt, error)
+	RetryDelivery(ctx context.Context, actorID, endpointID, deliveryID int64) error
+}
+
+// WebhookService управляет зарегистрированными webhook и журналом их доставок.
+// Саму отправку выполняет Dispatcher.
+type WebhookService struct {
+	repo         webhookrepo.Webhook_Repository
+	auditService auditService.Audit_Service
+}
+
+func NewWebhookService(repo webhookrepo.Webhook_Repository, auditService auditService.Audit_Service) *WebhookService {
+	return &WebhookService{
+		repo:         repo,
+		auditService: auditService,
+	}
+}
+
+// CreateEndpoint регистрирует webhook и генерирует секрет подписи.
+// Секрет возвращается в ответе только здесь и при ротации.
+func (s *WebhookService) CreateEndpoint(ctx context.Context, actorID int64, req request.CreateWebhookRequest) (*webhook.Endpoint, error) {
+	endpointURL, err := normalizeURL(ctx, req.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	eventTypes, err := normalizeEventTypes(req.EventTypes)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	description := validation.SanitizeInput(req.Description)
+	endpoint := &webhook.Endpoint{
+		URL:         endpointURL,
+		Secret:      secret,
+		EventTypes:  eventTypes,
+		Description: sql.NullString{String: description, Valid: description != ""},
+		IsActive:    true,
+		CreatedBy:   sql.NullInt64{Int64: actorID, Valid: actorID > 0},
+	}
+
+	if err := s.repo.CreateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, actorID, 0, audit.ActionAdminWebhookCreate, map[string]interface{}{
+		"webhook_id":  endpoint.ID,
+		"url":         endpoint.URL,
+		"event_types": endpoint.EventTypes,
+	})
+
+	return endpoint, nil
+}
+
+func (s *WebhookService) ListEndpoints(ctx context.Context) ([]webhook.Endpoint, error) {
+	return s.repo.ListEndpoints(ctx)
+}
+
+func (s *WebhookService) GetEndpoint(ctx context.Context, id int64) (*webhook.Endpoint, error) {
+	return s.repo.GetEndpoint(ctx, id)
+}
+
+func (s *WebhookService) UpdateEndpoint(ctx context.Context, actorID, id int64, req request.UpdateWebhookRequest) (*webhook.Endpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := map[string]interface{}{"webhook_id": id}
+
+	if req.URL != "" {
+		endpoint.URL, err = normalizeURL(ctx, req.URL)
+		if err != nil {
+			return nil, err
+		}
+		changes["url"] = endpoint.URL
+	}
+
+	if req.EventTypes != nil {
+		endpoint.EventTypes, err = normalizeEventTypes(req.EventTypes)
+		if err != nil {
+			return nil, err
+		}
+		changes["event_types"] = endpoint.EventTypes
+	}
+
+	if req.Description != nil {
+		description := validation.SanitizeInput(*req.Description)
+		endpoint.Description = sql.NullString{String: description, Valid: description != ""}
+		changes["description"] = description
+	}
+
+	if req.IsActive != nil {
+		endpoint.IsActive = *req.IsActive
+		changes["is_active"] = endpoint.IsActive
+	}
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, actorID, 0, audit.ActionAdminWebhookUpdate, changes)
+	return endpoint, nil
+}
+
+func (s *WebhookService) DeleteEndpoint(ctx context.Context, actorID, id int64) error {
+	if err := s.repo.DeleteEndpoint(ctx, id); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, actorID, 0, audit.ActionAdminWebhookDelete, map[string]interface{}{
+		"webhook_id": id,
+	})
+	return nil
+}
+
+// RotateSecret заменяет секрет подписи. Доставки, уже ожидающие повторной
+// отправки, будут подписаны новым секретом.
+func (s *WebhookService) RotateSecret(ctx context.Context, actorID, id int64) (*webhook.Endpoint, error) {
+	endpoint, err := s.repo.GetEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint.Secret, err = generateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateEndpoint(ctx, endpoint); err != nil {
+		return nil, err
+	}
+
+	s.auditService.Record(ctx, actorID, 0, audit.ActionAdminWebhookRotateSecret, map[string]interface{}{
+		"webhook_id": id,
+	})
+	return endpoint, nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, endpointID int64, status string, limit, offset int) ([]webhook.Delivery, int64, error) {
+	if _, err := s.repo.GetEndpoint(ctx, endpointID); err != nil {
+		return nil, 0, err
+	}
+
+	return s.repo.ListDeliveries(ctx, endpointID, status, limit, offset)
+}
+
+// GetDelivery возвращает доставку вместе с журналом попыток
+func (s *WebhookService) GetDelivery(ctx context.Context, endpointID, deliveryID int64) (*webhook.Delivery, []webhook.Attempt, error) {
+	delivery, err := s.repo.GetDelivery(ctx, endpointID, deliveryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attempts, err := s.repo.ListAttempts(ctx, deliveryID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return delivery, attempts, nil
+}
+
+// RetryDelivery возвращает доставку в очередь, в том числе из статуса dead
+func (s *WebhookService) RetryDelivery(ctx context.Context, actorID, endpointID, deliveryID int64) error {
+	delivery, err := s.repo.GetDelivery(ctx, endpointID, deliveryID)
+	if err != nil {
+		return err
+	}
+	if delivery.Status == webhook.DeliveryPending {
+		return ErrDeliveryPending
+	}
+
+	if err := s.repo.RequeueDelivery(ctx, endpointID, deliveryID); err != nil {
+		return err
+	}
+
+	s.auditService.Record(ctx, actorID, 0, audit.ActionAdminWebhookRetry, map[string]interface{}{
+		"webhook_id":  endpointID,
+		"delivery_id": deliveryID,
+		"status":      delivery.Status,
+	})
+	return nil
+}
+
+// normalizeURL проверяет схему и то, что хост указывает на публичный адрес.
+// При отправке адрес проверяется еще раз, см. NewHTTPClient.
+func normalizeURL(ctx context.Context, raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Hostname() == "" {
+		return "", ErrInvalidURL
+	}
+	if parsed.User != nil {
+		return "", ErrInvalidURL
+	}
+
+	if err := checkHost(ctx, parsed.Hostname()); err != nil {
+		return "", err
+	}
+	return parsed.String(), nil
+}
+
+// normalizeEventTypes проверяет типы событий и убирает повторы
+func normalizeEventTypes(eventTypes []string) (webhook.EventTypes, error) {
+	result := make(webhook.EventTypes, 0, len(eventTypes))
+	seen := make(map[string]bool, len(eventTypes))
+
+	for _, eventType := range eventTypes {
+		eventType = strings.TrimSpace(eventType)
+		if !outbox.IsValidEventType(eventType) {
+			return nil, ErrUnknownEventType.WithDetails(map[string]interface{}{
+				"event_type": eventType,
+				"allowed":    outbox.EventTypes,
+			})
+		}
+		if !seen[eventType] {
+			seen[eventType] = true
+			result = append(result, eventType)
+		}
+	}
+
+	return result, nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return secretPrefix + hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,85 @@
+package blob
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"auth_service/internal/config"
+	db "auth_service/internal/storage/minio"
+	"auth_service/pkg/apperror"
+)
+
+const (
+	BackendMinio      = "minio"
+	BackendFilesystem = "filesystem"
+	BackendMemory     = "memory"
+
+	// defaultSigningKey — значение storage.signingkey из настроек по умолчанию
+	defaultSigningKey = "change_me"
+)
+
+var (
+	ErrNotFound   = apperror.New(apperror.KindNotFound, "object_not_found", "object not found")
+	ErrInvalidKey = apperror.New(apperror.KindInvalid, "invalid_object_key", "invalid object key")
+)
+
+// BlobStore — хранилище файлов пользователей (фото профиля и миниатюры)
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error)
+	Delete(ctx context.Context, key string) error
+	Stat(ctx context.Context, key string) (*ObjectInfo, error)
+	// List вызывает fn для каждого объекта с префиксом prefix
+	List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error
+	PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error)
+	// PresignPut подписывает загрузку объекта ровно заданного типа и размера
+	PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (string, error)
+}
+
+type ObjectInfo struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	LastModified time.Time
+}
+
+// New создает хранилище по config.App.Storage.Backend. Для minio клиент
+// должен быть уже инициализирован (storage.BuildStorage).
+func New() (BlobStore, error) {
+	cfg := config.App.Storage
+
+	switch cfg.Backend {
+	case "", BackendMinio:
+		return NewMinioStore(db.MinioClient, db.PresignClient, config.App.Minio.Bucket), nil
+	case BackendFilesystem, BackendMemory:
+		// Ссылки на файлы подписывает сервис: с известным ключом их может выпустить кто угодно
+		if cfg.SigningKey == "" || cfg.SigningKey == defaultSigningKey {
+			return nil, fmt.Errorf("storage backend %q requires storage.signingkey to be set", cfg.Backend)
+		}
+		signer := NewURLSigner(cfg.PublicURL, cfg.SigningKey)
+		if cfg.Backend == BackendMemory {
+			return NewMemoryStore(signer), nil
+		}
+		return NewFilesystemStore(cfg.Path, signer)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Backend)
+	}
+}
+
+// cleanKey отклоняет ключи, которые могут выйти за пределы хранилища
+func cleanKey(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return "", ErrInvalidKey
+	}
+
+	return cleaned, nil
+}
@@ -0,0 +1,128 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore хранит объекты в памяти процесса. Подходит для локального
+// запуска и тестов, данные теряются при перезапуске.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	objects map[string]memoryObject
+	signer  *URLSigner
+}
+
+type memoryObject struct {
+	data        []byte
+	contentType string
+	modified    time.Time
+}
+
+func NewMemoryStore(signer *URLSigner) *MemoryStore {
+	return &MemoryStore{
+		objects: make(map[string]memoryObject),
+		signer:  signer,
+	}
+}
+
+func (s *MemoryStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	key, err := cleanKey(key)
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return fmt.Errorf("failed to read object: %w", err)
+	}
+	if size >= 0 && int64(len(data)) != size {
+		return fmt.Errorf("object size mismatch: expected %d, got %d", size, len(data))
+	}
+
+	s.mu.Lock()
+	s.objects[key] = memoryObject{data: data, contentType: contentType, modified: time.Now()}
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (io.ReadCloser, *ObjectInfo, error) {
+	s.mu.RLock()
+	object, ok := s.objects[key]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, nil, ErrNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(object.data)), object.info(key), nil
+}
+
+func (s *MemoryStore) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	delete(s.objects, key)
+	s.mu.Unlock()
+
+	return nil
+}
+
+func (s *MemoryStore) Stat(ctx context.Context, key string) (*ObjectInfo, error) {
+	s.mu.RLock()
+	object, ok := s.objects[key]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return object.info(key), nil
+}
+
+func (s *MemoryStore) List(ctx context.Context, prefix string, fn func(ObjectInfo) error) error {
+	s.mu.RLock()
+	infos := make([]ObjectInfo, 0, len(s.objects))
+	for key, object := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			infos = append(infos, *object.info(key))
+		}
+	}
+	s.mu.RUnlock()
+
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Key < infos[j].Key })
+
+	for _, info := range infos {
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *MemoryStore) PresignGet(ctx context.Context, key string, ttl time.Duration) (string, error) {
+	return s.signer.Sign("GET", key, "", 0, ttl), nil
+}
+
+func (s *MemoryStore) PresignPut(ctx context.Context, key, contentType string, size int64, ttl time.Duration) (string, error) {
+	return s.signer.Sign("PUT", key, contentType, size, ttl), nil
+}
+
+func (s *MemoryStore) urlSigner() *URLSigner {
+	return s.signer
+}
+
+func (o memoryObject) info(key string) *ObjectInfo {
+	return &ObjectInfo{
+		Key:          key,
+		Size:         int64(len(o.data)),
+		ContentType:  o.contentType,
+		LastModified: o.modified,
+	}
+}
@@ -0,0 +1,163 @@
+package blob
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"auth_service/pkg/apperror"
+)
+
+var errInvalidSignature = apperror.New(apperror.KindForbidden, "invalid_signature", "invalid or expired signature")
+
+// URLSigner выдает подписанные ссылки для хранилищ без собственного HTTP API
+// (filesystem, memory). Ссылки обслуживает LocalHandler.
+type URLSigner struct {
+	baseURL string
+	key     []byte
+}
+
+func NewURLSigner(baseURL, signingKey string) *URLSigner {
+	return &URLSigner{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		key:     []byte(signingKey),
+	}
+}
+
+func (s *URLSigner) Sign(method, key, contentType string, size int64, ttl time.Duration) string {
+	expires := time.Now().Add(ttl).Unix()
+
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", s.signature(method, key, contentType, size, expires))
+
+	return fmt.Sprintf("%s/%s?%s", s.baseURL, escapeKey(key), query.Encode())
+}
+
+func (s *URLSigner) verify(r *http.Request, key, contentType string, size int64) error {
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil || time.Now().Unix() > expires {
+		return errInvalidSignature
+	}
+
+	expected := s.signature(r.Method, key, contentType, size, expires)
+	if !hmac.Equal([]byte(expected), []byte(r.URL.Query().Get("signature"))) {
+		return errInvalidSignature
+	}
+
+	return nil
+}
+
+// signature покрывает метод, ключ и срок действия, а для PUT еще тип и размер тела
+func (s *URLSigner) signature(method, key, contentType string, size int64, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s\n%s\n%s\n%d\n%d", method, key, contentType, size, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *URLSigner) pathPrefix() string {
+	u, err := url.Parse(s.baseURL)
+	if err != nil || u.Path == "" {
+		return "/"
+	}
+	return u.Path + "/"
+}
+
+type localStore interface {
+	BlobStore
+	urlSigner() *URLSigner
+}
+
+// ErrorWriter отвечает клиенту ошибкой, в проекте это response.Error
+type ErrorWriter func(w http.ResponseWriter, r *http.Request, err error)
+
+// LocalHandler возвращает обработчик подписанных ссылок и префикс пути, на котором
+// его нужно смонтировать. Для minio ok == false: ссылки ведут прямо в MinIO.
+func LocalHandler(store BlobStore, writeError ErrorWriter) (pathPrefix string, handler http.Handler, ok bool) {
+	local, ok := store.(localStore)
+	if !ok {
+		return "", nil, false
+	}
+
+	signer := local.urlSigner()
+	prefix := signer.pathPrefix()
+
+	return prefix, http.StripPrefix(prefix, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, err := url.PathUnescape(r.URL.EscapedPath())
+		if err != nil {
+			writeError(w, r, ErrInvalidKey)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet, http.MethodHead:
+			serveGet(w, r, local, signer, key, writeError)
+		case http.MethodPut:
+			servePut(w, r, local, signer, key, writeError)
+		default:
+			writeError(w, r, apperror.ErrMethodNotAllowed)
+		}
+	})), true
+}
+
+func serveGet(w http.ResponseWriter, r *http.Request, store BlobStore, signer *URLSigner, key string, writeError ErrorWriter) {
+	// HEAD проверяется подписью GET, как в S3
+	req := *r
+	req.Method = http.MethodGet
+	if err := signer.verify(&req, key, "", 0); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	body, info, err := store.Get(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, ErrInvalidKey) {
+			err = ErrNotFound
+		}
+		writeError(w, r, err)
+		return
+	}
+	defer body.Close()
+
+	w.Header().Set("Content-Type", info.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(info.Size, 10))
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Set("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusOK)
+
+	if r.Method == http.MethodGet {
+		io.Copy(w, body)
+	}
+}
+
+func servePut(w http.ResponseWriter, r *http.Request, store BlobStore, signer *URLSigner, key string, writeError ErrorWriter) {
+	contentType := r.Header.Get("Content-Type")
+	if err := signer.verify(r, key, contentType, r.ContentLength); err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	err := store.Put(r.Context(), key, io.LimitReader(r.Body, r.ContentLength), r.ContentLength, contentType)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func escapeKey(key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
@@ -12,6 +12,11 @@ import (
 
 var MinioClient *minio.Client
 
+// PresignClient подписывает URL для клиентов. Он настроен на публичный домен
+// (minio.domain), поскольку хост входит в подпись, и не ходит в сеть:
+// регион задан явно, запрос расположения бакета не нужен.
+var PresignClient *minio.Client
+
 func InitMinio() {
 	cfg := config.App.Minio
 	var err error
@@ -19,12 +24,28 @@ func InitMinio() {
 	MinioClient, err = minio.New(cfg.EndPoint, &minio.Options{
 		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
 		Secure: cfg.UseSSL,
+		Region: cfg.Region,
 	})
 
 	if err != nil {
 		log.Fatalf("Failed to create MinIO client: %v", err)
 	}
 
+	presignEndpoint := cfg.Domain
+	if presignEndpoint == "" {
+		presignEndpoint = cfg.EndPoint
+	}
+
+	PresignClient, err = minio.New(presignEndpoint, &minio.Options{
+		Creds:  credentials.NewStaticV4(cfg.AccessKey, cfg.SecretKey, ""),
+		Secure: cfg.UseSSL,
+		Region: cfg.Region,
+	})
+
+	if err != nil {
+		log.Fatalf("Failed to create MinIO presign client: %v", err)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -39,28 +60,18 @@ func InitMinio() {
 	}
 
 	if !exists {
-		err = MinioClient.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{})
+		err = MinioClient.MakeBucket(ctx, cfg.Bucket, minio.MakeBucketOptions{Region: cfg.Region})
 
 		if err != nil {
 			log.Fatalf("Failed to create bucket: %v", err)
 		}
+	}
 
-		policy := `{
-            "Version": "2012-10-17",
-            "Statement": [
-                {
-                    "Effect": "Allow",
-                    "Principal": {"AWS": ["*"]},
-                    "Action": ["s3:GetObject"],
-                    "Resource": ["arn:aws:s3:::` + cfg.Bucket + `/*"]
-                }
-            ]
-        }`
-
-		err = MinioClient.SetBucketPolicy(ctx, cfg.Bucket, policy)
-		if err != nil {
-			log.Printf("Warning: Failed to set bucket policy: %v", err)
-		}
+	// Бакет приватный: раньше на него ставилась политика публичного чтения,
+	// пустая политика ее снимает. Фото отдаются по presigned GET URL.
+	err = MinioClient.SetBucketPolicy(ctx, cfg.Bucket, "")
+	if err != nil {
+		log.Printf("Warning: Failed to reset bucket policy: %v", err)
 	}
 
 	log.Println("Minio connected succesfully")
@@ -16,6 +16,8 @@ func BuildStorage() {
 
 	redis.InitRedis()
 
-	minio.InitMinio()
+	if backend := config.App.Storage.Backend; backend == "" || backend == "minio" {
+		minio.InitMinio()
+	}
 
 }
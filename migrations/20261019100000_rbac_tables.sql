@@ -0,0 +1,64 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE roles (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(64) UNIQUE NOT NULL,
+    description TEXT,
+    created_at  TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE permissions (
+    id          BIGSERIAL PRIMARY KEY,
+    name        VARCHAR(128) UNIQUE NOT NULL,
+    description TEXT,
+    created_at  TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE TABLE role_permissions (
+    role_id       BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    permission_id BIGINT NOT NULL REFERENCES permissions(id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+CREATE TABLE user_roles (
+    user_id    BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    role_id    BIGINT NOT NULL REFERENCES roles(id) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT NOW(),
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX idx_user_roles_role_id ON user_roles(role_id);
+
+INSERT INTO roles (name, description) VALUES
+    ('superadmin', 'Full access, including role management'),
+    ('admin', 'User management'),
+    ('support', 'Read-only access to user data');
+
+INSERT INTO permissions (name, description) VALUES
+    ('users.read', 'View users'),
+    ('users.write', 'Edit users'),
+    ('users.block', 'Block and unblock users'),
+    ('sessions.revoke', 'Revoke user sessions'),
+    ('roles.manage', 'Assign and revoke roles');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'superadmin';
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'admin'
+  AND p.name IN ('users.read', 'users.write', 'users.block', 'sessions.revoke');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name = 'support'
+  AND p.name IN ('users.read');
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE user_roles;
+DROP TABLE role_permissions;
+DROP TABLE permissions;
+DROP TABLE roles;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN is_blocked BOOLEAN NOT NULL DEFAULT false;
+
+CREATE INDEX idx_users_created_at ON users(created_at);
+
+CREATE TABLE audit_events (
+    id          BIGSERIAL PRIMARY KEY,
+    actor_id    BIGINT,
+    subject_id  BIGINT,
+    action      VARCHAR(64) NOT NULL,
+    ip          VARCHAR(45),
+    user_agent  TEXT,
+    metadata    JSONB NOT NULL DEFAULT '{}',
+    created_at  TIMESTAMPTZ DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_events_subject_id ON audit_events(subject_id, created_at DESC);
+CREATE INDEX idx_audit_events_actor_id ON audit_events(actor_id, created_at DESC);
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE audit_events;
+DROP INDEX idx_users_created_at;
+ALTER TABLE users DROP COLUMN is_blocked;
+-- +goose StatementEnd
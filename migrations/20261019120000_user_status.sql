@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN status        VARCHAR(32) NOT NULL DEFAULT 'active'
+        CHECK (status IN ('active', 'suspended', 'banned', 'pending_verification')),
+    ADD COLUMN status_reason TEXT,
+    ADD COLUMN status_until  TIMESTAMPTZ;
+
+UPDATE users SET status = 'suspended' WHERE is_blocked = true;
+
+ALTER TABLE users DROP COLUMN is_blocked;
+
+CREATE INDEX idx_users_status ON users(status) WHERE status <> 'active';
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users ADD COLUMN is_blocked BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE users SET is_blocked = true WHERE status IN ('suspended', 'banned');
+
+DROP INDEX idx_users_status;
+
+ALTER TABLE users
+    DROP COLUMN status_until,
+    DROP COLUMN status_reason,
+    DROP COLUMN status;
+-- +goose StatementEnd
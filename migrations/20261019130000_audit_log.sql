@@ -0,0 +1,45 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE audit_events ADD COLUMN request_id VARCHAR(64);
+
+CREATE INDEX idx_audit_events_action ON audit_events(action, created_at DESC);
+CREATE INDEX idx_audit_events_created_at ON audit_events(created_at DESC);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER audit_events_no_modify
+    BEFORE UPDATE OR DELETE ON audit_events
+    FOR EACH ROW
+    EXECUTE FUNCTION audit_events_append_only();
+
+CREATE TRIGGER audit_events_no_truncate
+    BEFORE TRUNCATE ON audit_events
+    FOR EACH STATEMENT
+    EXECUTE FUNCTION audit_events_append_only();
+
+INSERT INTO permissions (name, description) VALUES
+    ('audit.read', 'View the audit log');
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r, permissions p
+WHERE r.name IN ('superadmin', 'admin', 'support')
+  AND p.name = 'audit.read';
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM permissions WHERE name = 'audit.read';
+
+DROP TRIGGER audit_events_no_truncate ON audit_events;
+DROP TRIGGER audit_events_no_modify ON audit_events;
+DROP FUNCTION audit_events_append_only();
+
+DROP INDEX idx_audit_events_created_at;
+DROP INDEX idx_audit_events_action;
+
+ALTER TABLE audit_events DROP COLUMN request_id;
+-- +goose StatementEnd
@@ -0,0 +1,13 @@
+-- +goose Up
+-- +goose StatementBegin
+-- photo_object хранил полный публичный URL вида http://<domain>/<bucket>/<key>,
+-- оставляем только ключ объекта.
+UPDATE users
+SET photo_object = regexp_replace(photo_object, '^https?://[^/]+/[^/]+/', '')
+WHERE photo_object ~ '^https?://';
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+-- Домен и бакет в миграции неизвестны, ключи остаются ключами.
+SELECT 1;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN display_name VARCHAR(100),
+    ADD COLUMN birth_date   DATE,
+    ADD COLUMN locale       VARCHAR(35),
+    ADD COLUMN timezone     VARCHAR(64),
+    ADD COLUMN bio          TEXT;
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users
+    DROP COLUMN display_name,
+    DROP COLUMN birth_date,
+    DROP COLUMN locale,
+    DROP COLUMN timezone,
+    DROP COLUMN bio;
+-- +goose StatementEnd
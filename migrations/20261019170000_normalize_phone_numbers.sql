@@ -0,0 +1,21 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Отчет о номерах, которые не удалось привести к E.164 автоматически
+CREATE TABLE phone_normalization_conflicts (
+    id                  BIGSERIAL PRIMARY KEY,
+    user_id             BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    phone_number        VARCHAR(20) NOT NULL,
+    normalized          VARCHAR(20),
+    conflicting_user_id BIGINT REFERENCES users(id) ON DELETE CASCADE,
+    reason              VARCHAR(32) NOT NULL,
+    created_at          TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Сами номера приводит к E.164 команда cmd/normalizephones: нужен тот же разбор
+-- с регионом по умолчанию, что и при входе (phone.Normalize), в SQL его не повторить.
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+-- Исходное форматирование номеров не восстанавливается
+DROP TABLE phone_normalization_conflicts;
+-- +goose StatementEnd
@@ -0,0 +1,20 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE users
+    ADD COLUMN deleted_at    TIMESTAMPTZ,
+    ADD COLUMN anonymized_at TIMESTAMPTZ;
+
+-- Для уже удаленных аккаунтов льготный период отсчитывается от последнего изменения
+UPDATE users SET deleted_at = updated_at WHERE is_deleted = true;
+
+CREATE INDEX idx_users_pending_purge ON users (deleted_at)
+    WHERE is_deleted = true AND anonymized_at IS NULL;
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_users_pending_purge;
+
+ALTER TABLE users
+    DROP COLUMN deleted_at,
+    DROP COLUMN anonymized_at;
+-- +goose StatementEnd
@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Access токены, выданные раньше этой отметки, отклоняются
+ALTER TABLE users ADD COLUMN tokens_valid_after TIMESTAMPTZ;
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN tokens_valid_after;
+-- +goose StatementEnd
@@ -0,0 +1,65 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Журнал остается append-only. Единственное разрешенное изменение — обезличивание записей
+-- пользователя через redact_audit_events: IP и User-Agent очищаются, из метаданных
+-- удаляются ключи с контактными данными. Остальные поля менять нельзя.
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'UPDATE' THEN
+        IF current_setting('audit.redact_user', true) IN (OLD.subject_id::text, OLD.actor_id::text)
+           AND NEW.id = OLD.id
+           AND NEW.actor_id IS NOT DISTINCT FROM OLD.actor_id
+           AND NEW.subject_id IS NOT DISTINCT FROM OLD.subject_id
+           AND NEW.action = OLD.action
+           AND NEW.request_id IS NOT DISTINCT FROM OLD.request_id
+           AND NEW.created_at IS NOT DISTINCT FROM OLD.created_at
+           AND NEW.ip IS NULL
+           AND NEW.user_agent IS NULL
+           AND OLD.metadata @> NEW.metadata THEN
+            RETURN NEW;
+        END IF;
+    END IF;
+
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ language 'plpgsql';
+
+-- redact_audit_events обезличивает записи, где пользователь — субъект или исполнитель.
+-- Вызывается из UserRepository.Anonymize в той же транзакции, что и очистка users.
+CREATE OR REPLACE FUNCTION redact_audit_events(p_user_id BIGINT)
+RETURNS BIGINT
+SECURITY DEFINER
+SET search_path = public
+AS $$
+DECLARE
+    redacted BIGINT;
+BEGIN
+    PERFORM set_config('audit.redact_user', p_user_id::text, true);
+
+    UPDATE audit_events
+    SET ip = NULL,
+        user_agent = NULL,
+        metadata = metadata
+            - ARRAY['name', 'email', 'phone_number', 'new_phone_number', 'from', 'to']
+            -- причина блокировки — свободный текст; у неудачных входов reason — код, его оставляем
+            - CASE WHEN action = 'admin.user.block' THEN 'reason' ELSE '' END
+    WHERE subject_id = p_user_id OR actor_id = p_user_id;
+    GET DIAGNOSTICS redacted = ROW_COUNT;
+
+    PERFORM set_config('audit.redact_user', '', true);
+    RETURN redacted;
+END;
+$$ language 'plpgsql';
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP FUNCTION redact_audit_events(BIGINT);
+
+CREATE OR REPLACE FUNCTION audit_events_append_only()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_events is append-only';
+END;
+$$ language 'plpgsql';
+-- +goose StatementEnd
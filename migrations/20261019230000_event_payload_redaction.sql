@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Поиск событий и доставок пользователя при обезличивании (UserRepository.Anonymize)
+CREATE INDEX idx_outbox_events_user ON outbox_events (user_id);
+CREATE INDEX idx_webhook_deliveries_user ON webhook_deliveries (((payload->>'user_id')::bigint));
+-- +goose StatementEnd
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX idx_webhook_deliveries_user;
+DROP INDEX idx_outbox_events_user;
+-- +goose StatementEnd
@@ -0,0 +1,5 @@
+// Package authv1 — код, сгенерированный из api/proto/auth/v1/auth.proto.
+// Файлы *.pb.go не редактируются вручную.
+package authv1
+
+//go:generate protoc -I ../../../api/proto --go_out=../../.. --go_opt=module=auth_service --go-grpc_out=../../.. --go-grpc_opt=module=auth_service auth/v1/auth.proto
@@ -0,0 +1,95 @@
+package apperror
+
+import "errors"
+
+// Kind — класс ошибки, по нему слой ответа выбирает HTTP статус
+type Kind int
+
+const (
+	KindInternal Kind = iota
+	KindInvalid
+	KindUnauthorized
+	KindForbidden
+	KindNotFound
+	KindMethodNotAllowed
+	KindConflict
+	KindTooLarge
+	KindUnsupported
+	KindValidation
+	KindRateLimited
+)
+
+// Error — доменная ошибка со стабильным кодом. Code не меняется между версиями
+// и предназначен для клиентов, Message — для людей.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details map[string]interface{}
+
+	// Params подставляются в перевод сообщения из каталога i18n
+	Params []interface{}
+	cause  error
+}
+
+var (
+	ErrInternal         = New(KindInternal, "internal_error", "internal server error")
+	ErrInvalidBody      = New(KindInvalid, "invalid_body", "invalid request body")
+	ErrUnauthorized     = New(KindUnauthorized, "unauthorized", "unauthorized")
+	ErrForbidden        = New(KindForbidden, "forbidden", "forbidden")
+	ErrNotFound         = New(KindNotFound, "not_found", "resource not found")
+	ErrMethodNotAllowed = New(KindMethodNotAllowed, "method_not_allowed", "method not allowed")
+)
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// InvalidParameter — ошибка разбора параметра пути или строки запроса
+func InvalidParameter(name string) *Error {
+	err := New(KindInvalid, "invalid_parameter", "invalid "+name).WithDetails(map[string]interface{}{
+		"parameter": name,
+	})
+	err.Params = []interface{}{name}
+	return err
+}
+
+func (e *Error) Error() string {
+	if e.cause != nil {
+		return e.Message + ": " + e.cause.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.cause
+}
+
+// Is сравнивает ошибки по коду, поэтому errors.Is работает и для копий,
+// созданных Wrap и WithDetails
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Wrap возвращает копию ошибки с причиной. Причина попадает в лог, но не клиенту.
+func (e *Error) Wrap(cause error) *Error {
+	copied := *e
+	copied.cause = cause
+	return &copied
+}
+
+func (e *Error) WithDetails(details map[string]interface{}) *Error {
+	copied := *e
+	copied.Details = details
+	return &copied
+}
+
+// As находит *Error в цепочке ошибок
+func As(err error) (*Error, bool) {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr, true
+	}
+	return nil, false
+}
@@ -0,0 +1,121 @@
+package i18n
+
+// catalogs — сообщения по языкам. Английские тексты ошибок API заданы рядом
+// с самими ошибками (apperror.New), поэтому здесь только переводы кодов ошибок,
+// а правила валидации и уведомления описаны для всех языков.
+var catalogs = map[string]map[string]string{
+	LocaleEN: {
+		"validation.required":      "is required",
+		"validation.min":           "must be at least %s",
+		"validation.min.string":    "must be at least %s characters",
+		"validation.max":           "must be at most %s",
+		"validation.max.string":    "must be at most %s characters",
+		"validation.email":         "must be a valid email address",
+		"validation.startswith":    "must start with %q",
+		"validation.oneof":         "must be one of: %s",
+		"validation.default":       "failed the %q rule",
+		"account_suspended_until":  "account is suspended until %s",
+		"sms.phone_change_code":    "Phone number change code: %s",
+		"sms.account_restore_code": "Account restore code: %s",
+		"invalid_parameter":        "invalid %s",
+	},
+	LocaleRU: {
+		"validation.required":   "обязательное поле",
+		"validation.min":        "должно быть не меньше %s",
+		"validation.min.string": "должно содержать не меньше %s символов",
+		"validation.max":        "должно быть не больше %s",
+		"validation.max.string": "должно содержать не больше %s символов",
+		"validation.email":      "должно быть корректным email адресом",
+		"validation.startswith": "должно начинаться с %q",
+		"validation.oneof":      "должно быть одним из: %s",
+		"validation.default":    "не прошло проверку %q",
+
+		"sms.phone_change_code":    "Код подтверждения смены номера: %s",
+		"sms.account_restore_code": "Код восстановления аккаунта: %s",
+
+		"internal_error":     "внутренняя ошибка сервера",
+		"invalid_body":       "некорректное тело запроса",
+		"invalid_parameter":  "некорректный параметр %s",
+		"unauthorized":       "требуется авторизация",
+		"forbidden":          "доступ запрещен",
+		"not_found":          "ресурс не найден",
+		"method_not_allowed": "метод не поддерживается",
+		"validation_failed":  "ошибка валидации",
+
+		"authorization_required":       "требуется заголовок Authorization",
+		"invalid_authorization_format": "некорректный формат заголовка Authorization",
+		"invalid_token":                "токен недействителен или истек",
+		"token_revoked":                "токен отозван",
+		"impersonation_not_allowed":    "действие недоступно при входе от имени пользователя",
+		"service_unauthorized":         "требуется действительный токен сервиса в X-Service-Token",
+
+		"account_suspended":            "аккаунт приостановлен",
+		"account_suspended_until":      "аккаунт приостановлен до %s",
+		"account_banned":               "аккаунт заблокирован",
+		"account_pending_verification": "аккаунт ожидает подтверждения",
+
+		"user_not_found":         "пользователь не найден",
+		"deleted_user_not_found": "удаленный пользователь не найден",
+		"phone_number_taken":     "номер телефона уже используется",
+		"email_taken":            "email уже используется",
+		"role_not_found":         "роль не найдена",
+		"role_not_assigned":      "роль не назначена",
+
+		"invalid_credentials":     "неверный номер телефона или пароль",
+		"invalid_refresh_token":   "refresh токен недействителен или истек",
+		"invalid_password":        "неверный пароль",
+		"invalid_phone":           "некорректный номер телефона",
+		"same_phone_number":       "новый номер совпадает с текущим",
+		"otp_recently_sent":       "код уже отправлен, повторите попытку позже",
+		"no_pending_phone_change": "нет активной смены номера или код истек",
+		"invalid_otp_code":        "неверный код подтверждения",
+		"otp_attempts_exceeded":   "слишком много попыток, запросите новый код",
+
+		"account_deleted":            "аккаунт удален, подтвердите восстановление кодом из SMS",
+		"no_pending_account_restore": "нет активного восстановления аккаунта или код истек",
+
+		"invalid_email":           "некорректный email",
+		"invalid_name":            "имя должно содержать от 2 до 100 символов",
+		"invalid_display_name":    "display_name должно содержать от 1 до 100 символов",
+		"invalid_birth_date":      "birth_date должна быть в формате YYYY-MM-DD",
+		"birth_date_out_of_range": "недопустимая birth_date",
+		"invalid_locale":          "locale должен быть тегом языка BCP 47",
+		"invalid_timezone":        "timezone должен быть часовым поясом IANA",
+		"bio_too_long":            "bio должно содержать не больше 500 символов",
+		"name_required":           "имя нельзя удалить",
+
+		"photo_required":           "фото не загружено",
+		"photo_not_found":          "у профиля нет фото",
+		"photo_too_large":          "файл изображения слишком большой",
+		"unsupported_image_format": "неподдерживаемый формат изображения, допустимы только JPEG и PNG",
+		"invalid_image_dimensions": "размеры изображения вне допустимого диапазона",
+		"invalid_upload_key":       "некорректный ключ загруженного объекта",
+		"upload_not_found":         "загруженный объект не найден",
+		"unsupported_media_type":   "тип содержимого должен быть application/merge-patch+json",
+		"invalid_signature":        "подпись недействительна или истекла",
+		"object_not_found":         "объект не найден",
+
+		"export_not_found":   "выгрузка не найдена",
+		"export_queue_full":  "слишком много выгрузок в очереди, повторите попытку позже",
+		"invalid_object_key": "некорректный ключ объекта",
+
+		"cannot_revoke_own_superadmin":  "нельзя снять с себя роль superadmin",
+		"last_superadmin":               "нельзя снять роль с последнего superadmin",
+		"cannot_block_self":             "нельзя заблокировать себя",
+		"suspension_in_past":            "окончание приостановки должно быть в будущем",
+		"invalid_block_status":          "статус должен быть suspended или banned",
+		"cannot_impersonate_self":       "нельзя войти от имени самого себя",
+		"cannot_impersonate_superadmin": "нельзя войти от имени superadmin",
+		"cannot_manage_superadmin":      "нельзя менять данные или статус superadmin",
+		"cannot_manage_privileged_user": "нельзя менять данные или статус пользователя с ролью, которой у вас нет",
+
+		"webhook_not_found":          "webhook не найден",
+		"webhook_delivery_not_found": "доставка не найдена",
+		"webhook_delivery_pending":   "доставка уже ожидает отправки",
+		"invalid_webhook_url":        "url должен быть абсолютным адресом http или https",
+		"webhook_url_not_allowed":    "url должен указывать на публичный адрес",
+		"unknown_event_type":         "неизвестный тип события",
+
+		"too_many_ids": "слишком много ID в одном запросе",
+	},
+}
@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+
+	"auth_service/internal/config"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	LocaleEN = "en"
+	LocaleRU = "ru"
+)
+
+// supported — порядок совпадает с тегами matcher
+var supported = []string{LocaleEN, LocaleRU}
+
+var matcher = language.NewMatcher([]language.Tag{language.English, language.Russian})
+
+type contextKey struct{}
+
+// Default возвращает язык из конфига, если он поддерживается, иначе английский
+func Default() string {
+	if locale, ok := Normalize(config.App.I18n.DefaultLocale); ok {
+		return locale
+	}
+	return LocaleEN
+}
+
+// Normalize приводит тег BCP 47 (ru-RU, en_US) к поддерживаемому языку
+func Normalize(tag string) (string, bool) {
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "", false
+	}
+	return match(parsed)
+}
+
+// FromAcceptLanguage выбирает язык по заголовку Accept-Language с учетом весов q
+func FromAcceptLanguage(header string) (string, bool) {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(tags) == 0 {
+		return "", false
+	}
+	return match(tags...)
+}
+
+func match(tags ...language.Tag) (string, bool) {
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return "", false
+	}
+	return supported[index], true
+}
+
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext возвращает язык запроса или язык по умолчанию
+func FromContext(ctx context.Context) string {
+	if locale, ok := ctx.Value(contextKey{}).(string); ok {
+		return locale
+	}
+	return Default()
+}
+
+// Lookup ищет сообщение в каталоге языка, затем в английском
+func Lookup(locale, key string, args ...interface{}) (string, bool) {
+	message, ok := catalogs[locale][key]
+	if !ok {
+		message, ok = catalogs[LocaleEN][key]
+	}
+	if !ok {
+		return "", false
+	}
+
+	if len(args) > 0 {
+		message = fmt.Sprintf(message, args...)
+	}
+	return message, true
+}
+
+// T возвращает сообщение или сам ключ, если его нет в каталогах
+func T(locale, key string, args ...interface{}) string {
+	if message, ok := Lookup(locale, key, args...); ok {
+		return message
+	}
+	return key
+}
@@ -0,0 +1,154 @@
+package imageproc
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"auth_service/pkg/apperror"
+
+	"golang.org/x/image/draw"
+)
+
+const jpegQuality = 90
+
+var (
+	ErrUnsupportedFormat = apperror.New(apperror.KindInvalid, "unsupported_image_format", "unsupported image format, only JPEG and PNG are allowed")
+	ErrTooLarge          = apperror.New(apperror.KindTooLarge, "photo_too_large", "image file is too large")
+	ErrDimensions        = apperror.New(apperror.KindInvalid, "invalid_image_dimensions", "image dimensions are out of allowed range")
+)
+
+type Options struct {
+	MaxBytes       int64
+	MinDimension   int
+	MaxDimension   int
+	ThumbnailSizes []int
+}
+
+// Image — перекодированное изображение, готовое к сохранению
+type Image struct {
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+type Result struct {
+	Original   Image
+	Thumbnails map[int]Image
+}
+
+// Process декодирует изображение независимо от заявленного клиентом Content-Type,
+// проверяет размер файла и габариты, поворачивает по EXIF Orientation и перекодирует.
+// Перекодирование отбрасывает все метаданные, включая EXIF и GPS.
+// Для каждого размера из ThumbnailSizes создается уменьшенная копия,
+// вписанная в квадрат size x size; изображения меньше этого размера не увеличиваются.
+func Process(r io.Reader, opts Options) (*Result, error) {
+	data, err := io.ReadAll(io.LimitReader(r, opts.MaxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > opts.MaxBytes {
+		return nil, ErrTooLarge
+	}
+
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "jpeg" && format != "png") {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if cfg.Width < opts.MinDimension || cfg.Height < opts.MinDimension ||
+		cfg.Width > opts.MaxDimension || cfg.Height > opts.MaxDimension {
+		return nil, ErrDimensions
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+
+	if format == "jpeg" {
+		img = applyOrientation(img, jpegOrientation(data))
+	}
+
+	original, err := encode(img, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{
+		Original:   original,
+		Thumbnails: make(map[int]Image, len(opts.ThumbnailSizes)),
+	}
+
+	for _, size := range opts.ThumbnailSizes {
+		thumbnail, err := encode(resize(img, size), format)
+		if err != nil {
+			return nil, err
+		}
+		result.Thumbnails[size] = thumbnail
+	}
+
+	return result, nil
+}
+
+// ThumbnailKey возвращает ключ (или URL) миниатюры для ключа оригинала:
+// users/1/profile_1.jpg -> users/1/profile_1_256.jpg
+func ThumbnailKey(key string, size int) string {
+	ext := path.Ext(key)
+	return strings.TrimSuffix(key, ext) + "_" + strconv.Itoa(size) + ext
+}
+
+func encode(img image.Image, format string) (Image, error) {
+	var buf bytes.Buffer
+	result := Image{
+		Width:  img.Bounds().Dx(),
+		Height: img.Bounds().Dy(),
+	}
+
+	switch format {
+	case "png":
+		if err := png.Encode(&buf, img); err != nil {
+			return Image{}, fmt.Errorf("failed to encode png: %w", err)
+		}
+		result.ContentType = "image/png"
+		result.Ext = ".png"
+	default:
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return Image{}, fmt.Errorf("failed to encode jpeg: %w", err)
+		}
+		result.ContentType = "image/jpeg"
+		result.Ext = ".jpg"
+	}
+
+	result.Data = buf.Bytes()
+	return result, nil
+}
+
+func resize(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= size && height <= size {
+		return img
+	}
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
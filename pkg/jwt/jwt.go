@@ -4,24 +4,41 @@ import (
 	"auth_service/internal/config"
 	"errors"
 	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
 type Claims struct {
-	UserID int64 `json:"user_id"`
+	UserID int64    `json:"user_id"`
+	Roles  []string `json:"roles,omitempty"`
+	Act    *Actor   `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
 
-func GenerateAccessToken(userID int64) (string, error) {
+// Actor — claim act из RFC 8693: кто на самом деле действует от имени пользователя
+type Actor struct {
+	Subject string `json:"sub"`
+	UserID  int64  `json:"user_id"`
+}
+
+// IsImpersonation сообщает, выдан ли токен для работы сотрудника от имени пользователя
+func (c *Claims) IsImpersonation() bool {
+	return c.Act != nil
+}
+
+// GenerateAccessToken выдает access токен с iat = issuedAt. Обычно это текущее время;
+// сразу после отзыва сессий — отметка tokens_valid_after, которая может быть чуть впереди.
+func GenerateAccessToken(userID int64, roles []string, issuedAt time.Time) (string, error) {
 	cfg := config.App.JWT
 
 	claims := Claims{
 		UserID: userID,
+		Roles:  roles,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(parseDuration(cfg.AccessTTL))),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			ExpiresAt: jwt.NewNumericDate(issuedAt.Add(parseDuration(cfg.AccessTTL))),
+			IssuedAt:  jwt.NewNumericDate(issuedAt),
 		},
 	}
 
@@ -29,6 +46,30 @@ func GenerateAccessToken(userID int64) (string, error) {
 	return token.SignedString([]byte(cfg.AccessSecret))
 }
 
+// GenerateImpersonationToken выдает access токен пользователя userID с claim act,
+// указывающим на сотрудника actorID. Роли в токен не попадают, refresh токен не выдается.
+func GenerateImpersonationToken(userID, actorID int64) (string, time.Time, error) {
+	cfg := config.App.JWT
+	expiresAt := time.Now().Add(parseDuration(cfg.ImpersonationTTL))
+
+	claims := Claims{
+		UserID: userID,
+		Act: &Actor{
+			Subject: strconv.FormatInt(actorID, 10),
+			UserID:  actorID,
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   strconv.FormatInt(userID, 10),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(cfg.AccessSecret))
+	return signed, expiresAt, err
+}
+
 func GenerateRefreshToken(userID int64) (string, error) {
 	cfg := config.App.JWT
 
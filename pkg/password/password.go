@@ -1,9 +1,14 @@
 package password
 
 import (
+	"crypto/rand"
+	"math/big"
+
 	"golang.org/x/crypto/bcrypt"
 )
 
+const randomAlphabet = "abcdefghijkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
 func HashPassword(password string) (string, error) {
 	bytes, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	return string(bytes), err
@@ -13,3 +18,33 @@ func CheckPassword(password, hash string) bool {
 	err := bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
 	return err == nil
 }
+
+// GenerateRandom возвращает случайный пароль заданной длины
+// без визуально похожих символов (0/O, 1/l/I).
+func GenerateRandom(length int) (string, error) {
+	result := make([]byte, length)
+	max := big.NewInt(int64(len(randomAlphabet)))
+
+	for i := range result {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		result[i] = randomAlphabet[n.Int64()]
+	}
+
+	return string(result), nil
+}
+
+// GenerateNumericCode возвращает одноразовый цифровой код заданной длины
+func GenerateNumericCode(length int) (string, error) {
+	code := make([]byte, length)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		code[i] = byte('0' + n.Int64())
+	}
+	return string(code), nil
+}
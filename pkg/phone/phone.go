@@ -0,0 +1,53 @@
+package phone
+
+import (
+	"strings"
+
+	"auth_service/pkg/apperror"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+var ErrInvalid = apperror.New(apperror.KindInvalid, "invalid_phone", "invalid phone number format")
+
+// Normalize разбирает номер в международном ("+7 916 123-45-67") или национальном
+// ("8 (916) 123-45-67") формате и возвращает его в E.164 ("+79161234567").
+// defaultRegion (ISO 3166-1 alpha-2, например RU) используется для номеров без кода страны.
+func Normalize(input, defaultRegion string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", ErrInvalid
+	}
+
+	number, err := phonenumbers.Parse(input, strings.ToUpper(defaultRegion))
+	if err != nil {
+		return "", ErrInvalid
+	}
+
+	if !phonenumbers.IsValidNumber(number) {
+		return "", ErrInvalid
+	}
+
+	return phonenumbers.Format(number, phonenumbers.E164), nil
+}
+
+// Digits оставляет в строке только цифры, для поиска по части номера
+func Digits(input string) string {
+	var b strings.Builder
+	for _, r := range input {
+		if r >= '0' && r <= '9' {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// Mask оставляет последние 4 цифры номера ("***4567"). Используется там, где номер
+// нужен только для опознания человеком, например в журнале аудита.
+func Mask(input string) string {
+	digits := Digits(input)
+	if len(digits) <= 4 {
+		return "***"
+	}
+	return "***" + digits[len(digits)-4:]
+}
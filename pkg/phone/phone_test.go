@@ -0,0 +1,71 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		region  string
+		want    string
+		wantErr bool
+	}{
+		{"international", "+7 916 123-45-67", "RU", "+79161234567", false},
+		{"national with 8", "8 (916) 123-45-67", "RU", "+79161234567", false},
+		{"bare national", "9161234567", "RU", "+79161234567", false},
+		{"lowercase region", "8 916 123 45 67", "ru", "+79161234567", false},
+		{"international ignores region", "+1 202 555 0143", "RU", "+12025550143", false},
+		{"already e164", "+79161234567", "RU", "+79161234567", false},
+		{"empty", "", "RU", "", true},
+		{"spaces only", "   ", "RU", "", true},
+		{"garbage", "not a phone", "RU", "", true},
+		{"invalid number of valid length", "+7 000 123-45-67", "RU", "", true},
+		{"too short", "+7 916", "RU", "", true},
+		{"national without region", "8 916 123-45-67", "", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.input, tt.region)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalid) {
+					t.Fatalf("Normalize(%q) error = %v, want ErrInvalid", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Normalize(%q) error = %v", tt.input, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Normalize(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"e164", "+79161234567", "***4567"},
+		{"formatted", "+7 (916) 123-45-67", "***4567"},
+		{"five digits", "12345", "***2345"},
+		{"four digits", "1234", "***"},
+		{"short", "12", "***"},
+		{"empty", "", "***"},
+		{"no digits", "deleted-", "***"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Mask(tt.input); got != tt.want {
+				t.Fatalf("Mask(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
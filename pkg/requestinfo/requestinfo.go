@@ -0,0 +1,144 @@
+package requestinfo
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type contextKey struct{}
+
+type impersonatorKey struct{}
+
+// Info содержит сведения о клиенте, выполнившем запрос
+type Info struct {
+	IP        string
+	UserAgent string
+	RequestID string
+}
+
+// FromRequest собирает сведения о клиенте. Если клиент не передал X-Request-ID,
+// генерируется новый идентификатор.
+func FromRequest(r *http.Request) Info {
+	requestID := strings.TrimSpace(r.Header.Get(RequestIDHeader))
+	if requestID == "" || len(requestID) > 64 {
+		requestID = uuid.NewString()
+	}
+
+	return Info{
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+		RequestID: requestID,
+	}
+}
+
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
+
+// WithImpersonator отмечает, что запрос выполняет сотрудник actorID от имени пользователя
+func WithImpersonator(ctx context.Context, actorID int64) context.Context {
+	return context.WithValue(ctx, impersonatorKey{}, actorID)
+}
+
+func ImpersonatorFromContext(ctx context.Context) (int64, bool) {
+	actorID, ok := ctx.Value(impersonatorKey{}).(int64)
+	return actorID, ok
+}
+
+// trustedProxies — сети прокси, которым разрешено передавать адрес клиента
+// в X-Forwarded-For и X-Real-IP. Задаются при старте, см. SetTrustedProxies.
+var trustedProxies []*net.IPNet
+
+// SetTrustedProxies задает список CIDR доверенных прокси. Без него заголовки
+// X-Forwarded-For и X-Real-IP игнорируются: их может подделать любой клиент.
+func SetTrustedProxies(cidrs []string) error {
+	networks := make([]*net.IPNet, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		if !strings.Contains(cidr, "/") {
+			if ip := net.ParseIP(cidr); ip != nil && ip.To4() != nil {
+				cidr += "/32"
+			} else {
+				cidr += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		networks = append(networks, network)
+	}
+
+	trustedProxies = networks
+	return nil
+}
+
+// clientIP возвращает адрес клиента. Заголовки прокси учитываются, только если
+// соединение пришло от доверенного прокси: X-Forwarded-For читается справа налево
+// до первого адреса не из доверенных сетей. Результат всегда — разобранный IP.
+func clientIP(r *http.Request) string {
+	remote := parseIP(r.RemoteAddr)
+	if remote == nil {
+		return ""
+	}
+
+	if !isTrustedProxy(remote) {
+		return remote.String()
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := parseIP(hops[i])
+			if ip == nil {
+				break
+			}
+			client = ip
+			if !isTrustedProxy(ip) {
+				break
+			}
+		}
+		return client.String()
+	}
+
+	if realIP := parseIP(r.Header.Get("X-Real-IP")); realIP != nil {
+		return realIP.String()
+	}
+
+	return remote.String()
+}
+
+func isTrustedProxy(ip net.IP) bool {
+	for _, network := range trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseIP разбирает адрес с портом или без
+func parseIP(value string) net.IP {
+	value = strings.TrimSpace(value)
+	if host, _, err := net.SplitHostPort(value); err == nil {
+		value = host
+	}
+	return net.ParseIP(value)
+}
@@ -0,0 +1,56 @@
+package requestinfo
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	if err := SetTrustedProxies([]string{"10.0.0.0/8", "192.168.1.10"}); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { trustedProxies = nil })
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		forwarded  string
+		realIP     string
+		want       string
+	}{
+		{"direct client ignores headers", "203.0.113.7:5000", "1.2.3.4", "5.6.7.8", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.2:5000", "198.51.100.1", "", "198.51.100.1"},
+		{"spoofed leftmost hop", "10.0.0.2:5000", "1.1.1.1, 198.51.100.1, 10.0.0.3", "", "198.51.100.1"},
+		{"single trusted host", "192.168.1.10:80", "198.51.100.2", "", "198.51.100.2"},
+		{"invalid hop stops at proxy", "10.0.0.2:5000", "not-an-ip", "", "10.0.0.2"},
+		{"oversized header", "10.0.0.2:5000", strings.Repeat("a", 100), "", "10.0.0.2"},
+		{"real ip from trusted proxy", "10.0.0.2:5000", "", "2001:db8::1", "2001:db8::1"},
+		{"invalid real ip", "10.0.0.2:5000", "", "<script>", "10.0.0.2"},
+		{"untrusted private address", "192.168.1.11:80", "198.51.100.3", "", "192.168.1.11"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				r.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+
+			if got := clientIP(r); got != tt.want {
+				t.Errorf("clientIP = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetTrustedProxiesRejectsInvalidCIDR(t *testing.T) {
+	if err := SetTrustedProxies([]string{"10.0.0.0/33"}); err == nil {
+		t.Error("expected error for invalid CIDR")
+	}
+	trustedProxies = nil
+}
@@ -0,0 +1,47 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"auth_service/pkg/phone"
+)
+
+const ProviderLog = "log"
+
+// Sender отправляет SMS на номер в формате E.164
+type Sender interface {
+	Send(ctx context.Context, phone, message string) error
+}
+
+// New возвращает отправителя по имени провайдера из конфигурации
+func New(provider string) (Sender, error) {
+	switch provider {
+	case "", ProviderLog:
+		log.Println("Warning: SMS provider is \"log\", messages are not delivered and codes are masked in the log")
+		return LogSender{}, nil
+	default:
+		return nil, fmt.Errorf("unknown sms provider %q", provider)
+	}
+}
+
+// LogSender пишет сообщения в лог. Только для разработки. Цифры в тексте и номер
+// маскируются: лог не должен позволять подтвердить чужой номер или восстановить аккаунт.
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, to, message string) error {
+	log.Printf("sms to %s: %s", phone.Mask(to), maskDigits(message))
+	return nil
+}
+
+// maskDigits заменяет все цифры звездочками, в том числе код подтверждения
+func maskDigits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return '*'
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,87 @@
+package validation
+
+import (
+	"fmt"
+	"time"
+	_ "time/tzdata"
+	"unicode/utf8"
+
+	"auth_service/pkg/apperror"
+
+	"golang.org/x/text/language"
+)
+
+const (
+	maxDisplayNameLength = 100
+	maxBioLength         = 500
+	minAge               = 0
+	maxAge               = 150
+)
+
+var (
+	ErrInvalidName        = apperror.New(apperror.KindInvalid, "invalid_name", "name must be between 2 and 100 characters")
+	ErrInvalidDisplayName = apperror.New(apperror.KindInvalid, "invalid_display_name", fmt.Sprintf("display_name must be between 1 and %d characters", maxDisplayNameLength))
+	ErrBirthDateFormat    = apperror.New(apperror.KindInvalid, "invalid_birth_date", "birth_date must be in YYYY-MM-DD format")
+	ErrBirthDateRange     = apperror.New(apperror.KindInvalid, "birth_date_out_of_range", "birth_date is out of range")
+	ErrInvalidLocale      = apperror.New(apperror.KindInvalid, "invalid_locale", "locale must be a valid BCP 47 language tag")
+	ErrInvalidTimezone    = apperror.New(apperror.KindInvalid, "invalid_timezone", "timezone must be a valid IANA time zone")
+	ErrBioTooLong         = apperror.New(apperror.KindInvalid, "bio_too_long", fmt.Sprintf("bio must be at most %d characters", maxBioLength))
+)
+
+func ValidateName(name string) error {
+	length := utf8.RuneCountInString(name)
+	if length < 2 || length > 100 {
+		return ErrInvalidName
+	}
+	return nil
+}
+
+func ValidateDisplayName(displayName string) error {
+	if displayName == "" || utf8.RuneCountInString(displayName) > maxDisplayNameLength {
+		return ErrInvalidDisplayName
+	}
+	return nil
+}
+
+// ParseBirthDate разбирает дату рождения в формате YYYY-MM-DD.
+// Дата не может быть в будущем или раньше чем maxAge лет назад.
+func ParseBirthDate(value string, now time.Time) (time.Time, error) {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		return time.Time{}, ErrBirthDateFormat
+	}
+
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if date.After(today.AddDate(-minAge, 0, 0)) || date.Before(today.AddDate(-maxAge, 0, 0)) {
+		return time.Time{}, ErrBirthDateRange
+	}
+
+	return date, nil
+}
+
+// NormalizeLocale проверяет тег языка BCP 47 и приводит его к каноничной записи (ru-ru -> ru-RU)
+func NormalizeLocale(locale string) (string, error) {
+	tag, err := language.Parse(locale)
+	if err != nil || tag == language.Und {
+		return "", ErrInvalidLocale
+	}
+	return tag.String(), nil
+}
+
+// ValidateTimezone проверяет имя часового пояса по базе IANA (Europe/Moscow)
+func ValidateTimezone(timezone string) error {
+	if timezone == "" || timezone == "Local" {
+		return ErrInvalidTimezone
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return ErrInvalidTimezone
+	}
+	return nil
+}
+
+func ValidateBio(bio string) error {
+	if utf8.RuneCountInString(bio) > maxBioLength {
+		return ErrBioTooLong
+	}
+	return nil
+}
@@ -0,0 +1,120 @@
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"auth_service/pkg/i18n"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var structValidator = newStructValidator()
+
+// FieldError описывает нарушение одного правила из тега validate
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+
+	messageKey  string
+	messageArgs []interface{}
+}
+
+// ValidationErrors — все нарушения, найденные в запросе
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, fieldErr := range e {
+		messages = append(messages, fieldErr.Field+": "+fieldErr.Message)
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Localize возвращает копию ошибок с сообщениями на языке locale
+func (e ValidationErrors) Localize(locale string) ValidationErrors {
+	result := make(ValidationErrors, len(e))
+	for i, fieldErr := range e {
+		if fieldErr.messageKey != "" {
+			fieldErr.Message = i18n.T(locale, fieldErr.messageKey, fieldErr.messageArgs...)
+		}
+		result[i] = fieldErr
+	}
+	return result
+}
+
+func newStructValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// В ошибках используем имена полей из JSON, а не из Go
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+
+	return v
+}
+
+// ValidateStruct проверяет теги validate и возвращает ValidationErrors
+func ValidateStruct(s interface{}) error {
+	err := structValidator.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return err
+	}
+
+	result := make(ValidationErrors, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		key, args := ruleMessage(fieldErr)
+		result = append(result, FieldError{
+			Field:       fieldPath(fieldErr.Namespace()),
+			Rule:        fieldErr.Tag(),
+			Message:     i18n.T(i18n.LocaleEN, key, args...),
+			messageKey:  key,
+			messageArgs: args,
+		})
+	}
+
+	return result
+}
+
+// fieldPath убирает имя структуры из пути: SignUpRequest.phone_number -> phone_number
+func fieldPath(namespace string) string {
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+// ruleMessage возвращает ключ сообщения в каталоге i18n и его параметры
+func ruleMessage(fieldErr validator.FieldError) (string, []interface{}) {
+	suffix := ""
+	if fieldErr.Kind() == reflect.String {
+		suffix = ".string"
+	}
+
+	switch fieldErr.Tag() {
+	case "required", "email":
+		return "validation." + fieldErr.Tag(), nil
+	case "min", "max":
+		return "validation." + fieldErr.Tag() + suffix, []interface{}{fieldErr.Param()}
+	case "startswith":
+		return "validation.startswith", []interface{}{fieldErr.Param()}
+	case "oneof":
+		return "validation.oneof", []interface{}{strings.Join(strings.Fields(fieldErr.Param()), ", ")}
+	default:
+		return "validation.default", []interface{}{fieldErr.Tag()}
+	}
+}
@@ -1,11 +1,14 @@
 package validation
 
 import (
-	"fmt"
 	"regexp"
 	"strings"
+
+	"auth_service/pkg/apperror"
 )
 
+var ErrInvalidEmail = apperror.New(apperror.KindInvalid, "invalid_email", "invalid email format")
+
 func ValidateEmail(email string) bool {
 	regex := regexp.MustCompile(`^[a-zA-Z0-9._%+-]+@[a-zA-Z0-9.-]+\.[a-zA-Z]{2,}$`)
 	return regex.MatchString(email)
@@ -25,11 +28,11 @@ func SanitizeInput(input string) string {
 
 func ValidateUpdateProfileRequest(name, email string) error {
 	if name != "" && (len(name) < 2 || len(name) > 100) {
-		return fmt.Errorf("name must be between 2 and 100 characters")
+		return ErrInvalidName
 	}
 
 	if email != "" && !ValidateEmail(email) {
-		return fmt.Errorf("invalid email format")
+		return ErrInvalidEmail
 	}
 
 	return nil